@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/ENIACore/media_library_manager/internal/archive"
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/database"
+	"github.com/ENIACore/media_library_manager/internal/logger"
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+	"github.com/ENIACore/media_library_manager/internal/pending"
+	"github.com/ENIACore/media_library_manager/internal/planner"
+)
+
+// Extracts archive sets beneath root into staging under ManagerPath and imports their contents
+// A set is recorded as processed by its first volume and its staging removed once every action succeeds,
+// sets with failed actions keep their staging for inspection and are extracted again on the next run
+func importArchives(root *metadata.Entry, db *database.DB, queue *pending.Queue, tpl *naming.Template, strategy planner.Strategy, cfg *config.Config, log *slog.Logger) error {
+	for _, set := range archive.Group(root) {
+		first := set.Volumes[0]
+		if db.IsProcessed(first) || queue.Contains(first) {
+			continue
+		}
+		staging := archive.Staging(cfg.ManagerPath, set)
+		if cfg.DryRun {
+			fmt.Printf("extract %q -> %q (dry run, contents not planned)\n", first, staging)
+			continue
+		}
+
+		fmt.Printf("extract %q -> %q\n", first, staging)
+		if err := os.RemoveAll(staging); err != nil {
+			return fmt.Errorf("clear staging %s, %w", staging, err)
+		}
+		if err := archive.Extract(set, staging, log); err != nil {
+			fmt.Printf("failed %s: %v\n", first, err)
+			os.RemoveAll(staging)
+			continue
+		}
+		staged, err := parser.ParseTree(staging, nil, 0, log)
+		if err != nil {
+			return err
+		}
+		res, err := importTree(staged, db, queue, tpl, strategy, cfg, log)
+		if err != nil {
+			return err
+		}
+		if len(res.Failed) > 0 {
+			fmt.Printf("keeping %s, %d actions failed\n", staging, len(res.Failed))
+			continue
+		}
+
+		err = db.Add(database.Record{
+			Source:		first,
+			Dest:		staging,
+			Strategy:	database.Extract,
+			Session:	logger.Session(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(staging); err != nil {
+			return fmt.Errorf("remove staging %s, %w", staging, err)
+		}
+	}
+	return nil
+}
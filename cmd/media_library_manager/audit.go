@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/ENIACore/media_library_manager/internal/audit"
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+// Reports library files that do not follow the active naming template
+func runAudit(args []string, cfg *config.Config, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	plan := flags.Bool("plan", false, "print fix-up plan of moves after report")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := auditLibrary(cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("audited %d videos in %s, %d issues\n", report.Videos, report.Root, len(report.Issues))
+	for _, issue := range report.Issues {
+		fmt.Printf("[%s] %s\n", issue.Kind, issue.Path)
+		if issue.Detail != "" {
+			fmt.Printf("    %s\n", issue.Detail)
+		}
+		if issue.Expected != "" {
+			fmt.Printf("    expected %s\n", issue.Expected)
+		}
+	}
+
+	if *plan {
+		fmt.Println("\nfix-up plan:")
+		for _, issue := range report.Plan() {
+			fmt.Printf("mv %q %q\n", issue.Path, issue.Expected)
+		}
+	}
+	return nil
+}
+
+func auditLibrary(cfg *config.Config, logger *slog.Logger) (audit.Report, error) {
+	tpl, err := naming.New(cfg.MovieTemplate, cfg.EpisodeTemplate, cfg.DailyTemplate, cfg.MusicTemplate)
+	if err != nil {
+		return audit.Report{}, err
+	}
+
+	root, err := parser.ParseTree(cfg.LibraryPath, nil, 0, logger)
+	if err != nil {
+		return audit.Report{}, err
+	}
+
+	return audit.Audit(root, tpl, logger), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/gaps"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+// Reports missing and duplicate episode numbers per season of LibraryPath
+func runGaps(args []string, cfg *config.Config, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("gaps", flag.ContinueOnError)
+	countsPath := flags.String("counts", filepath.Join(cfg.ManagerPath, "episode_counts.json"), "JSON file of expected episodes per series season")
+	all := flags.Bool("all", false, "also list complete seasons")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	counts, err := gaps.LoadCountFile(*countsPath)
+	if err != nil {
+		return err
+	}
+	root, err := parser.ParseTree(cfg.LibraryPath, nil, 0, logger)
+	if err != nil {
+		return err
+	}
+
+	series := ""
+	for _, season := range gaps.Find(root, counts, logger) {
+		if !*all && !season.HasGaps() {
+			continue
+		}
+		if season.Series != series {
+			series = season.Series
+			fmt.Println(series)
+		}
+		fmt.Printf("    %s\n", season)
+	}
+	return nil
+}
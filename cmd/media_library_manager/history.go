@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/database"
+)
+
+// Prints most recent processed records, optionally limited to one session
+func runHistory(args []string, cfg *config.Config, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	count := flags.Int("n", 50, "number of records to show, 0 for all")
+	session := flags.String("session", "", "only show records of session timestamp")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := database.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+
+	records := db.History(*session, *count)
+	logger.Info("listing history", "records", len(records))
+	printRecords(records)
+	return nil
+}
+
+// Prints records whose source or library destination is, or lies beneath, the given path
+func runWhereis(args []string, cfg *config.Config, logger *slog.Logger) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one path")
+	}
+
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("resolve path %s, %w", args[0], err)
+	}
+
+	db, err := database.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+
+	records := db.WhereIs(path)
+	logger.Info("looking up path", "path", path, "records", len(records))
+	if len(records) == 0 {
+		fmt.Printf("%s has not been processed\n", path)
+		return nil
+	}
+	printRecords(records)
+	return nil
+}
+
+func printRecords(records []database.Record) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "IMPORTED\tSESSION\tSTRATEGY\tSOURCE\tDEST")
+	for _, rec := range records {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			rec.ImportedAt.Format(time.DateTime), rec.Session, rec.Strategy, rec.Source, rec.Dest)
+	}
+	writer.Flush()
+}
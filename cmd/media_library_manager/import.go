@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/classifier"
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/database"
+	"github.com/ENIACore/media_library_manager/internal/executor"
+	"github.com/ENIACore/media_library_manager/internal/extractor"
+	"github.com/ENIACore/media_library_manager/internal/logger"
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+	"github.com/ENIACore/media_library_manager/internal/pending"
+	"github.com/ENIACore/media_library_manager/internal/planner"
+	"github.com/ENIACore/media_library_manager/internal/quality"
+	"github.com/ENIACore/media_library_manager/internal/subtitle"
+)
+
+// Places media beneath path, MediaPath by default, into the library skipping already processed sources
+// and sources waiting in the pending queue
+func runImport(args []string, cfg *config.Config, log *slog.Logger) error {
+	if len(args) > 1 {
+		return errors.New("expected at most one path")
+	}
+	path := cfg.MediaPath
+	if len(args) == 1 {
+		abs, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("resolve path %s, %w", args[0], err)
+		}
+		path = abs
+	}
+
+	strategy := planner.Strategy(cfg.ImportStrategy)
+	if strategy != planner.Hardlink && strategy != planner.Copy {
+		return fmt.Errorf("unsupported import strategy %q, want hardlink or copy", cfg.ImportStrategy)
+	}
+	if cfg.SubtitleFormat != "" && !subtitle.CanWrite(strings.ToUpper(cfg.SubtitleFormat)) {
+		return fmt.Errorf("unsupported subtitle format %q, want srt or vtt", cfg.SubtitleFormat)
+	}
+
+	db, err := database.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+	queue, err := pending.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+	tpl, err := naming.New(cfg.MovieTemplate, cfg.EpisodeTemplate, cfg.DailyTemplate, cfg.MusicTemplate)
+	if err != nil {
+		return err
+	}
+	root, err := parser.ParseTree(path, nil, 0, log)
+	if err != nil {
+		return err
+	}
+	if _, err := importTree(root, db, queue, tpl, strategy, cfg, log); err != nil {
+		return err
+	}
+	if cfg.ExtractArchives {
+		return importArchives(root, db, queue, tpl, strategy, cfg, log)
+	}
+	return nil
+}
+
+// Classifies and places media of parsed tree root, recording every placed file as processed
+func importTree(root *metadata.Entry, db *database.DB, queue *pending.Queue, tpl *naming.Template, strategy planner.Strategy, cfg *config.Config, log *slog.Logger) (executor.Result, error) {
+	classify(root)
+
+	var err error
+	plan := planner.Build(root, cfg.LibraryPath, cfg.MusicPath, tpl, strategy, log).
+		Without(db.IsProcessed).
+		Without(queue.Contains).
+		Upgrade(supersedes(db, log))
+	if cfg.VerifyChecksums {
+		if plan, err = rejectCorrupt(plan, queue, cfg, log); err != nil {
+			return executor.Result{}, err
+		}
+	}
+	if cfg.ConvertSubtitles {
+		plan = convertSubtitles(plan, log)
+	}
+	if cfg.SubtitleFormat != "" {
+		plan = addSubtitleFormat(plan, strings.ToUpper(cfg.SubtitleFormat), log)
+	}
+	res, err := executePlan(plan, cfg, log)
+	if err != nil {
+		return res, err
+	}
+
+	for _, action := range res.Done {
+		err := db.Add(database.Record{
+			Source:		action.Source,
+			Dest:		action.Dest,
+			Strategy:	string(action.Strategy),
+			Session:	logger.Session(),
+		})
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// Runs the classifier passes the planner relies on over parsed tree root
+func classify(root *metadata.Entry) {
+	classifier.AssignSeasons(root)
+	classifier.PairSubtitles(root)
+	classifier.AssignAlbums(root)
+	classifier.AssignParts(root)
+}
+
+// Returns true if source is the same quality as the library file at dest with a higher revision
+// Library names carry no release tags, so dest is judged by the name it was imported from
+func supersedes(db *database.DB, log *slog.Logger) func(source string, dest string) bool {
+	return func(source string, dest string) bool {
+		existing := dest
+		if rec, ok := db.Origin(dest); ok {
+			existing = rec.Source
+		}
+		return quality.Supersedes(extractor.ExtractMedia(source, log), extractor.ExtractMedia(existing, log))
+	}
+}
+
+// Prints plan and executes it, journaling under the current session
+func executePlan(plan planner.Plan, cfg *config.Config, log *slog.Logger) (executor.Result, error) {
+	for _, skip := range plan.Skipped {
+		fmt.Printf("skip %s: %s\n", skip.Path, skip.Reason)
+	}
+	for _, action := range plan.Actions {
+		if action.Replace {
+			fmt.Printf("%s %q -> %q (replaces existing)\n", action.Strategy, action.Source, action.Dest)
+			continue
+		}
+		fmt.Printf("%s %q -> %q\n", action.Strategy, action.Source, action.Dest)
+	}
+
+	res, err := executor.Execute(plan, cfg.ManagerPath, logger.Session(), cfg.DryRun, log)
+	if err != nil {
+		return res, err
+	}
+	if len(plan.Actions) == 0 {
+		fmt.Println("nothing to do")
+		return res, nil
+	}
+	if cfg.DryRun {
+		fmt.Printf("dry run, %d actions not executed\n", len(plan.Actions))
+		return res, nil
+	}
+
+	for _, failure := range res.Failed {
+		fmt.Printf("failed %s: %v\n", failure.Action.Source, failure.Err)
+	}
+	fmt.Printf("%d actions done, %d failed, journal %s\n", len(res.Done), len(res.Failed), res.Journal)
+	return res, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/database"
+	"github.com/ENIACore/media_library_manager/internal/inventory"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+// Exports every library video with its media info and prints aggregate statistics
+func runInventory(args []string, cfg *config.Config, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("inventory", flag.ContinueOnError)
+	format := flags.String("format", "json", "export format, json or csv")
+	output := flags.String("o", "", "export file, stdout if not set")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("unsupported format %q, want json or csv", *format)
+	}
+
+	db, err := database.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+	root, err := parser.ParseTree(cfg.LibraryPath, nil, 0, logger)
+	if err != nil {
+		return err
+	}
+
+	origin := func(path string) (string, bool) {
+		rec, ok := db.Origin(path)
+		return rec.Source, ok
+	}
+	items, err := inventory.Collect(root, origin, logger)
+	if err != nil {
+		return err
+	}
+
+	// Statistics go to stderr when the export itself is written to stdout
+	var out io.Writer = os.Stdout
+	var statsOut io.Writer = os.Stderr
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("create %s, %w", *output, err)
+		}
+		defer file.Close()
+		out = file
+		statsOut = os.Stdout
+	}
+
+	if *format == "csv" {
+		err = inventory.WriteCSV(out, items)
+	} else {
+		err = inventory.WriteJSON(out, items)
+	}
+	if err != nil {
+		return err
+	}
+
+	printStats(statsOut, inventory.Summarize(items))
+	return nil
+}
+
+func printStats(w io.Writer, stats inventory.Stats) {
+	writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(writer, "items\t%d\t%s\n", stats.Items, formatSize(stats.TotalSize))
+	fmt.Fprintf(writer, "re-encode candidates\t%d\t%s\n", stats.Reencodable, formatSize(stats.ReencodableSize))
+
+	fmt.Fprintln(writer, "\nRESOLUTION\tITEMS\tSIZE")
+	for _, res := range sortedKeys(stats.CountByResolution) {
+		fmt.Fprintf(writer, "%s\t%d\t%s\n", res, stats.CountByResolution[res], formatSize(stats.SizeByResolution[res]))
+	}
+
+	fmt.Fprintln(writer, "\nCODEC\tITEMS")
+	for _, codec := range sortedKeys(stats.CountByCodec) {
+		fmt.Fprintf(writer, "%s\t%d\n", codec, stats.CountByCodec[codec])
+	}
+	writer.Flush()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
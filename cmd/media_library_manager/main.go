@@ -1,10 +1,65 @@
 package main
 
 import (
-	//"github.com/ENIACore/media_library_manager/internal/logger"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/logger"
 )
 
+type command struct {
+	usage	string
+	run		func(args []string, cfg *config.Config, logger *slog.Logger) error
+}
+
+var commands = map[string]command{
+	"audit":	{usage: "audit [-plan]", run: runAudit},
+	"gaps":		{usage: "gaps [-counts file] [-all]", run: runGaps},
+	"history":	{usage: "history [-n count] [-session timestamp]", run: runHistory},
+	"import":	{usage: "import [path]", run: runImport},
+	"inventory":	{usage: "inventory [-format json|csv] [-o file]", run: runInventory},
+	"remove":	{usage: "remove <path>", run: runRemove},
+	"reorganize":	{usage: "reorganize", run: runReorganize},
+	"undo":		{usage: "undo [-session timestamp]", run: runUndo},
+	"verify":	{usage: "verify [path]", run: runVerify},
+	"whereis":	{usage: "whereis <path>", run: runWhereis},
+}
+
 func main() {
-	//logger := logger.GetLogger()
-	//logger.Info("test here")
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	log := logger.NewLogger(cfg).With("command", os.Args[1])
+	if err := cmd.run(os.Args[2:], cfg, log); err != nil {
+		log.Error("command failed", "error", err)
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: media_library_manager <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
 }
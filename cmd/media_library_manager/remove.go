@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/database"
+	"github.com/ENIACore/media_library_manager/internal/executor"
+	"github.com/ENIACore/media_library_manager/internal/planner"
+)
+
+// Cleans up after a removed torrent, library links and copies placed from path, including files
+// extracted from its archives, are removed and its records forgotten
+// Moved files are kept, as are destinations since replaced by a file from another source
+func runRemove(args []string, cfg *config.Config, log *slog.Logger) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one path")
+	}
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("resolve path %s, %w", args[0], err)
+	}
+
+	db, err := database.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+
+	// Origins are looked up before the records are gone
+	var actions []planner.Action
+	for _, rec := range db.Placed(path) {
+		if origin, ok := db.Origin(rec.Dest); !ok || origin.Source != rec.Source {
+			continue
+		}
+		action := planner.Action{Strategy: planner.Strategy(rec.Strategy), Source: rec.Source, Dest: rec.Dest}
+		// Archive records point at their removed staging, moved files are the library's only copy
+		if action.Strategy == planner.Move {
+			fmt.Printf("keeping moved %q\n", action.Dest)
+		}
+		if action.Strategy != planner.Hardlink && action.Strategy != planner.Copy && action.Strategy != planner.Convert {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	if cfg.DryRun {
+		for _, action := range actions {
+			fmt.Printf("remove %s %q (dry run)\n", action.Strategy, action.Dest)
+		}
+		return nil
+	}
+
+	records, err := db.Remove(path)
+	if err != nil {
+		return err
+	}
+	log.Info("forgot records", "path", path, "records", len(records))
+
+	removed, err := executor.Unlink(actions, cfg.LibraryPath, log)
+	for _, action := range removed {
+		fmt.Printf("removed %s %q\n", action.Strategy, action.Dest)
+	}
+	fmt.Printf("%d records forgotten, %d library files removed\n", len(records), len(removed))
+	return err
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/database"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+	"github.com/ENIACore/media_library_manager/internal/planner"
+)
+
+// Moves files already in LibraryPath, with their sidecars, to follow the active naming template
+func runReorganize(args []string, cfg *config.Config, log *slog.Logger) error {
+	if len(args) > 0 {
+		return errors.New("expected no arguments")
+	}
+
+	db, err := database.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+	tpl, err := naming.New(cfg.MovieTemplate, cfg.EpisodeTemplate, cfg.DailyTemplate, cfg.MusicTemplate)
+	if err != nil {
+		return err
+	}
+	root, err := parser.ParseTree(cfg.LibraryPath, nil, 0, log)
+	if err != nil {
+		return err
+	}
+	classify(root)
+
+	plan := planner.Build(root, cfg.LibraryPath, cfg.MusicPath, tpl, planner.Move, log)
+	res, err := executePlan(plan, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	moves := make(map[string]string, len(res.Done))
+	for _, action := range res.Done {
+		moves[action.Source] = action.Dest
+	}
+	return db.Relocate(moves)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/extractor"
+	"github.com/ENIACore/media_library_manager/internal/planner"
+	"github.com/ENIACore/media_library_manager/internal/subtitle"
+)
+
+// Returns plan where text subtitles not encoded in UTF-8 are placed as converted copies
+// Subtitles whose encoding cannot be detected are reported and placed unchanged
+func convertSubtitles(plan planner.Plan, log *slog.Logger) planner.Plan {
+	res := plan
+	res.Actions = make([]planner.Action, len(plan.Actions))
+	for i, action := range plan.Actions {
+		res.Actions[i] = action
+
+		ext := strings.ToUpper(strings.TrimPrefix(filepath.Ext(action.Source), "."))
+		if !subtitle.IsText(ext) || (ext == "SUB" && extractor.IsVobSub(action.Source)) {
+			continue
+		}
+		data, err := os.ReadFile(action.Source)
+		if err != nil {
+			log.Warn("unable to read subtitle", "path", action.Source, "error", err)
+			fmt.Printf("unreadable subtitle %s: %v\n", action.Source, err)
+			continue
+		}
+
+		encoding, err := subtitle.DetectEncoding(data)
+		if err != nil {
+			log.Warn("undetectable subtitle encoding", "path", action.Source)
+			fmt.Printf("undetectable encoding %s: placed unchanged\n", action.Source)
+			continue
+		}
+		if encoding != subtitle.UTF8 {
+			log.Debug("converting subtitle", "path", action.Source, "encoding", encoding)
+			res.Actions[i].Strategy = planner.Convert
+		}
+	}
+	return res
+}
+
+// Returns plan where text subtitles in other formats are also placed as converted copies in format
+// Copies whose destination is already taken, by the plan or on disk, are reported and left out
+func addSubtitleFormat(plan planner.Plan, format string, log *slog.Logger) planner.Plan {
+	taken := make(map[string]bool)
+	for _, action := range plan.Actions {
+		taken[action.Dest] = true
+	}
+
+	res := plan
+	res.Actions = nil
+	for _, action := range plan.Actions {
+		res.Actions = append(res.Actions, action)
+
+		ext := strings.ToUpper(strings.TrimPrefix(filepath.Ext(action.Source), "."))
+		if ext == format || !subtitle.CanRead(ext) {
+			continue
+		}
+		dest := strings.TrimSuffix(action.Dest, filepath.Ext(action.Dest)) + "." + strings.ToLower(format)
+		if _, err := os.Lstat(dest); taken[dest] || err == nil {
+			log.Debug("subtitle format already placed", "path", action.Source, "dest", dest)
+			fmt.Printf("skip %s copy of %s: %s exists\n", strings.ToLower(format), action.Source, dest)
+			continue
+		}
+
+		log.Debug("converting subtitle format", "path", action.Source, "format", format)
+		taken[dest] = true
+		res.Actions = append(res.Actions, planner.Action{Strategy: planner.Convert, Source: action.Source, Dest: dest, Video: action.Video})
+	}
+	return res
+}
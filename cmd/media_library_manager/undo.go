@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/database"
+	"github.com/ENIACore/media_library_manager/internal/executor"
+	"github.com/ENIACore/media_library_manager/internal/planner"
+)
+
+// Reverses the journal of a session, the latest session by default
+func runUndo(args []string, cfg *config.Config, log *slog.Logger) error {
+	flags := flag.NewFlagSet("undo", flag.ContinueOnError)
+	session := flags.String("session", "", "session timestamp to undo, latest by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	journal := executor.Journal(cfg.ManagerPath, *session)
+	if *session == "" {
+		latest, err := executor.Latest(cfg.ManagerPath)
+		if err != nil {
+			return err
+		}
+		journal = latest
+	}
+
+	if cfg.DryRun {
+		fmt.Printf("dry run, not undoing %s\n", journal)
+		return nil
+	}
+
+	db, err := database.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+
+	undone, undoErr := executor.Undo(journal, cfg.LibraryPath, log)
+	moves := make(map[string]string)
+	for _, action := range undone {
+		fmt.Printf("undid %s %q -> %q\n", action.Strategy, action.Source, action.Dest)
+		if action.Strategy == planner.Move {
+			moves[action.Dest] = action.Source
+			continue
+		}
+		if _, err := db.Remove(action.Source); err != nil {
+			return err
+		}
+	}
+	if err := db.Relocate(moves); err != nil {
+		return err
+	}
+	return undoErr
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/ENIACore/media_library_manager/internal/checksum"
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/extractor"
+	"github.com/ENIACore/media_library_manager/internal/logger"
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+	"github.com/ENIACore/media_library_manager/internal/pending"
+	"github.com/ENIACore/media_library_manager/internal/planner"
+)
+
+// Hashes videos and audio beneath path, MediaPath by default, whose name carries a CRC32 and reports mismatches
+// Unless dry run, mismatching files are queued as corrupt and files that match again leave the queue
+func runVerify(args []string, cfg *config.Config, log *slog.Logger) error {
+	if len(args) > 1 {
+		return errors.New("expected at most one path")
+	}
+	path := cfg.MediaPath
+	if len(args) == 1 {
+		abs, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("resolve path %s, %w", args[0], err)
+		}
+		path = abs
+	}
+
+	queue, err := pending.Open(cfg.ManagerPath)
+	if err != nil {
+		return err
+	}
+	root, err := parser.ParseTree(path, nil, 0, log)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	results := checksum.Verify(root, log)
+	for _, res := range results {
+		if res.Match() {
+			fmt.Printf("ok %s\n", res.Path)
+			if !cfg.DryRun {
+				if _, err := queue.Remove(res.Path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		failed++
+		fmt.Printf("corrupt %s: %s\n", res.Path, mismatch(res))
+		if !cfg.DryRun {
+			if err := queueCorrupt(queue, res); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("%d files verified, %d failed\n", len(results), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d files failed verification", failed)
+	}
+	return nil
+}
+
+// Hashes video and audio sources carrying a CRC32 in their name and returns plan without those that mismatch
+// and their sidecars, which carry the video's CRC32 in their name. Unless dry run, mismatching files are queued as corrupt
+func rejectCorrupt(plan planner.Plan, queue *pending.Queue, cfg *config.Config, log *slog.Logger) (planner.Plan, error) {
+	corrupt := make(map[string]bool)
+	for _, action := range plan.Actions {
+		if kind := extractor.ExtractPath(action.Source, log).Type; kind != metadata.Video && kind != metadata.Audio {
+			continue
+		}
+		crc := extractor.ExtractMedia(action.Source, log).CRC
+		if crc == "" {
+			continue
+		}
+
+		res := checksum.Check(action.Source, crc)
+		if res.Match() {
+			continue
+		}
+		corrupt[action.Source] = true
+		fmt.Printf("corrupt %s: %s\n", res.Path, mismatch(res))
+		if !cfg.DryRun {
+			if err := queueCorrupt(queue, res); err != nil {
+				return plan, err
+			}
+		}
+	}
+
+	return plan.WithoutVideos(func(source string) bool { return corrupt[source] }), nil
+}
+
+func queueCorrupt(queue *pending.Queue, res checksum.Result) error {
+	return queue.Add(pending.Item{
+		Path:		res.Path,
+		Reason:		pending.Corrupt,
+		Detail:		mismatch(res),
+		Session:	logger.Session(),
+	})
+}
+
+func mismatch(res checksum.Result) string {
+	if res.Err != nil {
+		return res.Err.Error()
+	}
+	return fmt.Sprintf("expected crc %s, got %s", res.Expected, res.Actual)
+}
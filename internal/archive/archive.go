@@ -0,0 +1,212 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+// Formats of archive sets
+const (
+	RAR		= "RAR"
+	ZIP		= "ZIP"
+	SevenZip	= "7Z"
+)
+
+// Volumes of one archive, extraction starts at the first
+type Set struct {
+	Name	string		// Name shared by all volumes, e.g. "Movie.2020.1080p-GRP"
+	Format	string		// RAR, ZIP or 7Z
+	Volumes	[]string	// Paths in extraction order
+}
+
+// Volume name forms, the first group is the set name and the second the volume number if any
+// A lone ".rar", ".zip" or ".7z" is opened first, old style ".r00" and split ".z01" volumes follow it
+var volumeForms = []struct{
+	format	string
+	regex	*regexp.Regexp
+}{
+	{RAR, regexp.MustCompile(`(?i)^(.+)\.part(\d+)\.rar$`)},
+	{RAR, regexp.MustCompile(`(?i)^(.+)\.rar()$`)},
+	{RAR, regexp.MustCompile(`(?i)^(.+)\.r(\d{2,3})$`)},
+	{ZIP, regexp.MustCompile(`(?i)^(.+)\.zip()$`)},
+	{ZIP, regexp.MustCompile(`(?i)^(.+)\.z(\d{2})$`)},
+	{ZIP, regexp.MustCompile(`(?i)^(.+)\.zip\.(\d{3})$`)},
+	{SevenZip, regexp.MustCompile(`(?i)^(.+)\.7z()$`)},
+	{SevenZip, regexp.MustCompile(`(?i)^(.+)\.7z\.(\d{3})$`)},
+}
+
+// External tools able to extract each format, in order of preference
+var tools = map[string][]string{
+	RAR:		{"unrar", "7z", "7zz"},
+	ZIP:		{"7z", "7zz", "7za"},
+	SevenZip:	{"7z", "7zz", "7za"},
+}
+
+// Returns archive sets formed by archive volumes beneath root, volumes of a set share its directory
+func Group(root *metadata.Entry) []Set {
+	type volume struct {
+		path	string
+		number	int		// -1 for a volume without number
+	}
+	type key struct {
+		dir		string
+		name	string
+		format	string
+	}
+
+	sets := make(map[key][]volume)
+	var order []key
+	walkFiles(root, func(file *metadata.Entry) {
+		if file.Type != metadata.Archive {
+			return
+		}
+		name := filepath.Base(file.PathInfo.Source)
+		for _, form := range volumeForms {
+			match := form.regex.FindStringSubmatch(name)
+			if match == nil {
+				continue
+			}
+			number := -1
+			if match[2] != "" {
+				number, _ = strconv.Atoi(match[2])
+			}
+			k := key{dir: filepath.Dir(file.PathInfo.Source), name: match[1], format: form.format}
+			if _, ok := sets[k]; !ok {
+				order = append(order, k)
+			}
+			sets[k] = append(sets[k], volume{path: file.PathInfo.Source, number: number})
+			return
+		}
+	})
+
+	res := make([]Set, 0, len(order))
+	for _, k := range order {
+		volumes := sets[k]
+		sort.Slice(volumes, func(i, j int) bool {
+			return volumes[i].number < volumes[j].number
+		})
+		set := Set{Name: k.name, Format: k.format}
+		for _, v := range volumes {
+			set.Volumes = append(set.Volumes, v.path)
+		}
+		res = append(res, set)
+	}
+	return res
+}
+
+// Returns staging directory for set under managerPath
+// The name carries a hash of the set's directory, sets sharing a name in different directories do not collide
+func Staging(managerPath string, set Set) string {
+	hash := sha256.Sum256([]byte(filepath.Dir(set.Volumes[0])))
+	return filepath.Join(managerPath, "staging", fmt.Sprintf("%s-%x", set.Name, hash[:6]))
+}
+
+// Extracts set into dest, single ZIP archives in Go, everything else with an external tool
+func Extract(set Set, dest string, logger *slog.Logger) error {
+	log := logger.With("func", "Extract")
+	log.Info("extracting archive", "archive", set.Volumes[0], "format", set.Format, "volumes", len(set.Volumes), "dest", dest)
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return fmt.Errorf("create staging dir %s, %w", dest, err)
+	}
+	if set.Format == ZIP && len(set.Volumes) == 1 && strings.EqualFold(filepath.Ext(set.Volumes[0]), ".zip") {
+		return extractZip(set.Volumes[0], dest)
+	}
+
+	tool, err := findTool(set.Format)
+	if err != nil {
+		return err
+	}
+	args := []string{"x", "-y", "-o" + dest, set.Volumes[0]}
+	if filepath.Base(tool) == "unrar" {
+		args = []string{"x", "-o+", "-y", "-idq", set.Volumes[0], dest + string(filepath.Separator)}
+	}
+	out, err := exec.Command(tool, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("extract %s with %s, %w, %s", set.Volumes[0], filepath.Base(tool), err, bytes.TrimSpace(out))
+	}
+
+	log.Debug("successfully extracted archive", "archive", set.Volumes[0], "tool", tool)
+	return nil
+}
+
+// Returns path of the first external tool found able to extract format
+func findTool(format string) (string, error) {
+	for _, name := range tools[format] {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no tool found to extract %s archives, install %s", format, strings.Join(tools[format], " or "))
+}
+
+// Extracts ZIP archive at path into dest, entries escaping dest are rejected
+func extractZip(path string, dest string) error {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("open zip %s, %w", path, err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		target := filepath.Join(dest, file.Name)
+		if rel, err := filepath.Rel(dest, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("zip entry %s escapes %s", file.Name, dest)
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("create dir %s, %w", target, err)
+			}
+			continue
+		}
+		if err := extractZipFile(file, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(file *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("create dir %s, %w", filepath.Dir(target), err)
+	}
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("open zip entry %s, %w", file.Name, err)
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create file %s, %w", target, err)
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return fmt.Errorf("extract zip entry %s, %w", file.Name, err)
+	}
+	return out.Close()
+}
+
+// Calls fn for every file beneath entry, a file root is its own only file
+func walkFiles(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	if entry.Children == nil {
+		fn(entry)
+		return
+	}
+	for _, child := range entry.Children {
+		walkFiles(child, fn)
+	}
+}
@@ -0,0 +1,144 @@
+package archive
+
+import (
+	"archive/zip"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+func TestGroup(t *testing.T) {
+	media := createDummyTree(t, []string{
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.part02.rar",
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.part01.rar",
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.nfo",
+		"Show.S01E01.720p-GRP/show.s01e01.r01",
+		"Show.S01E01.720p-GRP/show.s01e01.rar",
+		"Show.S01E01.720p-GRP/show.s01e01.r00",
+		"Extras/extras.7z.002",
+		"Extras/extras.7z.001",
+		"Extras/artwork.zip",
+	})
+	root, err := parser.ParseTree(media, nil, 0, slog.Default())
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	expected := map[string]Set{
+		"Movie.2020.1080p-GRP":	{Name: "Movie.2020.1080p-GRP", Format: RAR, Volumes: []string{
+			"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.part01.rar",
+			"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.part02.rar",
+		}},
+		"show.s01e01":			{Name: "show.s01e01", Format: RAR, Volumes: []string{
+			"Show.S01E01.720p-GRP/show.s01e01.rar",
+			"Show.S01E01.720p-GRP/show.s01e01.r00",
+			"Show.S01E01.720p-GRP/show.s01e01.r01",
+		}},
+		"extras":				{Name: "extras", Format: SevenZip, Volumes: []string{
+			"Extras/extras.7z.001",
+			"Extras/extras.7z.002",
+		}},
+		"artwork":				{Name: "artwork", Format: ZIP, Volumes: []string{
+			"Extras/artwork.zip",
+		}},
+	}
+
+	sets := Group(root)
+	if len(sets) != len(expected) {
+		t.Errorf("Group len = %v, want %v, got %+v", len(sets), len(expected), sets)
+	}
+	for _, set := range sets {
+		for i, volume := range set.Volumes {
+			set.Volumes[i], _ = filepath.Rel(media, volume)
+		}
+		if !reflect.DeepEqual(set, expected[set.Name]) {
+			t.Errorf("Group set = %+v, want %+v", set, expected[set.Name])
+		}
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Movie.2020.zip")
+	createZip(t, path, map[string]string{
+		"Movie.2020/Movie.2020.mkv":	"video",
+		"Movie.2020/Movie.2020.srt":	"1\n00:00:01,000 --> 00:00:02,000\nHello\n",
+	})
+	dest := filepath.Join(dir, "staging")
+
+	if err := Extract(Set{Name: "Movie.2020", Format: ZIP, Volumes: []string{path}}, dest, slog.Default()); err != nil {
+		t.Fatalf("Extract returns error %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "Movie.2020/Movie.2020.mkv"))
+	if err != nil {
+		t.Fatalf("Extract did not write entry, error %v", err)
+	}
+	if string(data) != "video" {
+		t.Errorf("Extract entry = %q, want %q", data, "video")
+	}
+
+	escaping := filepath.Join(dir, "escape.zip")
+	createZip(t, escaping, map[string]string{"../outside.mkv": "video"})
+	if err := Extract(Set{Name: "escape", Format: ZIP, Volumes: []string{escaping}}, dest, slog.Default()); err == nil {
+		t.Errorf("Extract with escaping entry returns nil error, want error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside.mkv")); err == nil {
+		t.Errorf("Extract wrote escaping entry outside of dest")
+	}
+}
+
+func createZip(t *testing.T, path string, files map[string]string) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Unable to create dummy zip %v, error %v", path, err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Unable to add zip entry %v, error %v", name, err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatalf("Unable to write zip entry %v, error %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Unable to close dummy zip %v, error %v", path, err)
+	}
+}
+
+func createDummyTree(t *testing.T, files []string) string {
+	dir := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unable to create dummy dir %v, error %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+		}
+	}
+	return dir
+}
+
+func TestStaging(t *testing.T) {
+	first := Set{Name: "Movie.2020.1080p-GRP", Format: RAR, Volumes: []string{"/media/a/Movie.2020.1080p-GRP.rar"}}
+	second := Set{Name: "Movie.2020.1080p-GRP", Format: RAR, Volumes: []string{"/media/b/Movie.2020.1080p-GRP.rar"}}
+
+	staging := Staging("/manager", first)
+	if filepath.Dir(staging) != "/manager/staging" {
+		t.Errorf("Staging = %v, want dir /manager/staging", staging)
+	}
+	if staging != Staging("/manager", first) {
+		t.Errorf("Staging of same set differs between calls")
+	}
+	if staging == Staging("/manager", second) {
+		t.Errorf("Staging of same named sets in different dirs = %v for both", staging)
+	}
+}
@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/extractor"
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+)
+
+type IssueKind int8
+
+// Problems found with existing library files
+const (
+	NonCompliantName	IssueKind = iota	// File name differs from naming template
+	NonCompliantFolder						// File name matches but folders differ from naming template
+	OutsideSeasonFolder						// Episode file not inside a season directory
+	OrphanSubtitle							// Subtitle file without a matching video file
+	OrphanVobSub							// VobSub .idx or .sub without its other half
+	Unresolved								// Naming template could not be rendered for file
+)
+
+func (kind IssueKind) String() string {
+	switch kind {
+	case NonCompliantName:
+		return "non-compliant name"
+	case NonCompliantFolder:
+		return "non-compliant folder"
+	case OutsideSeasonFolder:
+		return "outside season folder"
+	case OrphanSubtitle:
+		return "orphan subtitle"
+	case OrphanVobSub:
+		return "orphan vobsub"
+	case Unresolved:
+		return "unresolved"
+	}
+	return "unknown"
+}
+
+type Issue struct {
+	Kind		IssueKind
+	Path		string
+	Expected	string	// Path the file should have, "" if unknown
+	Detail		string
+}
+
+type Report struct {
+	Root	string
+	Videos	int		// Number of video files checked
+	Issues	[]Issue
+}
+
+// Returns issues that can be fixed by moving the file to its expected path
+func (report *Report) Plan() []Issue {
+	var res []Issue
+	seen := make(map[string]bool)
+	for _, issue := range report.Issues {
+		if issue.Expected == "" || seen[issue.Path] {
+			continue
+		}
+		seen[issue.Path] = true
+		res = append(res, issue)
+	}
+	return res
+}
+
+// Validates every file beneath root against the naming template
+// Root is expected to be the parsed LibraryPath
+func Audit(root *metadata.Entry, tpl *naming.Template, logger *slog.Logger) Report {
+	log := logger.With("func", "Audit")
+	log.Info("auditing library", "path", root.PathInfo.Source)
+
+	report := Report{Root: root.PathInfo.Source}
+	walk(root, func(entry *metadata.Entry) {
+		switch entry.Type {
+		case metadata.Video:
+			report.Issues = append(report.Issues, auditVideo(entry, root.PathInfo.Source, tpl)...)
+			if entry.Bonus == "" {
+				report.Videos++
+			}
+		case metadata.Subtitle:
+			if !hasMatchingVideo(entry) {
+				report.Issues = append(report.Issues, Issue{
+					Kind:	OrphanSubtitle,
+					Path:	entry.PathInfo.Source,
+					Detail:	"no video in directory shares subtitle name",
+				})
+			}
+			if isVobSub(entry) && entry.VobSubHalf() == nil {
+				report.Issues = append(report.Issues, Issue{
+					Kind:	OrphanVobSub,
+					Path:	entry.PathInfo.Source,
+					Detail:	"other half of VobSub .idx/.sub pair is missing",
+				})
+			}
+		}
+	})
+
+	log.Info("finished library audit", "videos", report.Videos, "issues", len(report.Issues))
+	return report
+}
+
+func auditVideo(entry *metadata.Entry, libraryPath string, tpl *naming.Template) []Issue {
+	// Extras are left where they are, Jellyfin resolves them by folder
+	if entry.Bonus != "" {
+		return nil
+	}
+
+	var issues []Issue
+	info := entry.Inherited()
+
+	if naming.IsEpisode(info) && (entry.Parent == nil || entry.Parent.Season == nil) {
+		issues = append(issues, Issue{
+			Kind:	OutsideSeasonFolder,
+			Path:	entry.PathInfo.Source,
+			Detail:	fmt.Sprintf("episode %d is not inside a season directory", naming.NewFields(info).Episode),
+		})
+	}
+
+	rel, err := tpl.Path(info, entry.Ext)
+	if err != nil {
+		return append(issues, Issue{
+			Kind:	Unresolved,
+			Path:	entry.PathInfo.Source,
+			Detail:	err.Error(),
+		})
+	}
+
+	expected := filepath.Join(libraryPath, rel)
+	if expected == entry.PathInfo.Source {
+		return issues
+	}
+
+	kind := NonCompliantName
+	if filepath.Base(expected) == filepath.Base(entry.PathInfo.Source) {
+		kind = NonCompliantFolder
+	}
+	return append(issues, Issue{
+		Kind:		kind,
+		Path:		entry.PathInfo.Source,
+		Expected:	expected,
+	})
+}
+
+// Returns true if entry is a VobSub .idx or a .sub holding VobSub rather than MicroDVD data
+func isVobSub(entry *metadata.Entry) bool {
+	return entry.Ext == "IDX" || (entry.Ext == "SUB" && extractor.IsVobSub(entry.PathInfo.Source))
+}
+
+// Returns true if a sibling video's name, without ext, prefixes the subtitle's name
+func hasMatchingVideo(subtitle *metadata.Entry) bool {
+	if subtitle.Parent == nil {
+		return false
+	}
+
+	name := strings.ToLower(filepath.Base(subtitle.PathInfo.Source))
+	for _, sibling := range subtitle.Parent.Children {
+		if sibling.Type != metadata.Video {
+			continue
+		}
+		video := strings.ToLower(filepath.Base(sibling.PathInfo.Source))
+		stem := strings.TrimSuffix(video, filepath.Ext(video))
+		if strings.HasPrefix(name, stem+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Calls fn for entry and every descendant, parents before children
+func walk(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	fn(entry)
+	for _, child := range entry.Children {
+		walk(child, fn)
+	}
+}
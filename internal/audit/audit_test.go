@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+func TestAudit(t *testing.T) {
+	library := createDummyLibrary(t, []string{
+		"Movies/The Matrix (1999)/The Matrix (1999).mkv",
+		"Movies/The Matrix (1999)/The Matrix (1999).en.srt",
+		"Movies/The Matrix (1999)/The Matrix (1999).en.idx",
+		"Movies/matrix.reloaded.2003.1080p.mkv",
+		"Shows/Show/Show S01E01.mkv",
+		"Shows/Show/Season 01/Show S01E02.mkv",
+		"Shows/Show/Season 01/orphan.srt",
+	})
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(library, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+	tpl, err := naming.New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("naming.New returns error %v", err)
+	}
+
+	report := Audit(root, tpl, logger)
+	if report.Videos != 4 {
+		t.Errorf("Audit Videos = %v, want 4", report.Videos)
+	}
+
+	expected := map[string]IssueKind{
+		"Movies/matrix.reloaded.2003.1080p.mkv":	NonCompliantName,
+		"Shows/Show/Show S01E01.mkv":				OutsideSeasonFolder,
+		"Shows/Show/Season 01/orphan.srt":			OrphanSubtitle,
+		"Movies/The Matrix (1999)/The Matrix (1999).en.idx":	OrphanVobSub,
+	}
+	found := make(map[string]bool)
+	for _, issue := range report.Issues {
+		rel, _ := filepath.Rel(library, issue.Path)
+		if kind, ok := expected[rel]; ok && kind == issue.Kind {
+			found[rel] = true
+		}
+	}
+	for rel, kind := range expected {
+		if !found[rel] {
+			t.Errorf("Audit missing %v issue for %v, got %+v", kind, rel, report.Issues)
+		}
+	}
+
+	plan := report.Plan()
+	if len(plan) != 2 {
+		t.Fatalf("Plan len = %v, want 2, got %+v", len(plan), plan)
+	}
+	want := filepath.Join(library, "Movies/Matrix Reloaded (2003)/Matrix Reloaded (2003).mkv")
+	if plan[0].Expected != want {
+		t.Errorf("Plan[0].Expected = %v, want %v", plan[0].Expected, want)
+	}
+	want = filepath.Join(library, "Shows/Show/Season 01/Show S01E01.mkv")
+	if plan[1].Expected != want || plan[1].Kind != NonCompliantFolder {
+		t.Errorf("Plan[1] = %+v, want %v non-compliant folder", plan[1], want)
+	}
+}
+
+// Creates empty files at relative paths inside temporary library
+func createDummyLibrary(t *testing.T, files []string) string {
+	dir := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unable to create dummy dir %v, error %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+		}
+	}
+	return dir
+}
@@ -0,0 +1,75 @@
+package checksum
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+// Outcome of hashing a single file against the CRC32 found in its name
+type Result struct {
+	Path		string
+	Expected	string	// Upper cased CRC32 from the name
+	Actual		string	// Upper cased CRC32 of the contents, "" if the file could not be read
+	Err			error
+}
+
+// Returns true if file could be read and its contents match the expected checksum
+func (res Result) Match() bool {
+	return res.Err == nil && res.Actual == res.Expected
+}
+
+// Returns upper cased hex CRC32 (IEEE) of file contents
+func CRC32(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open %s, %w", path, err)
+	}
+	defer file.Close()
+
+	hash := crc32.NewIEEE()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash %s, %w", path, err)
+	}
+	return fmt.Sprintf("%08X", hash.Sum32()), nil
+}
+
+// Hashes file at path and compares it with expected checksum
+func Check(path string, expected string) Result {
+	actual, err := CRC32(path)
+	return Result{Path: path, Expected: expected, Actual: actual, Err: err}
+}
+
+// Returns result of every video and audio file beneath root whose name carries a CRC32
+// Subtitles and other sidecars carry the CRC32 of their video and are not hashed
+func Verify(root *metadata.Entry, logger *slog.Logger) []Result {
+	log := logger.With("func", "Verify")
+	log.Info("verifying checksums", "path", root.PathInfo.Source)
+
+	var results []Result
+	walk(root, func(entry *metadata.Entry) {
+		if entry.Children != nil || entry.CRC == "" || (entry.Type != metadata.Video && entry.Type != metadata.Audio) {
+			return
+		}
+		res := Check(entry.PathInfo.Source, entry.CRC)
+		if !res.Match() {
+			log.Warn("checksum mismatch", "path", res.Path, "expected", res.Expected, "actual", res.Actual, "error", res.Err)
+		}
+		results = append(results, res)
+	})
+
+	log.Info("finished verifying checksums", "files", len(results))
+	return results
+}
+
+// Calls fn for entry and every descendant, parents before children
+func walk(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	fn(entry)
+	for _, child := range entry.Children {
+		walk(child, fn)
+	}
+}
@@ -0,0 +1,58 @@
+package checksum
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+func TestCRC32(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.mkv")
+	if err := os.WriteFile(path, []byte("123456789"), 0644); err != nil {
+		t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+	}
+
+	// Standard CRC32 check value of "123456789"
+	if sum, err := CRC32(path); err != nil || sum != "CBF43926" {
+		t.Errorf("CRC32 = %v, %v, want CBF43926, nil", sum, err)
+	}
+	if _, err := CRC32(path + ".missing"); err == nil {
+		t.Errorf("CRC32 of missing file returns nil error, want error")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"[Group] Show - 01 [CBF43926].mkv":	"123456789",
+		"[Group] Show - 02 [00000000].mkv":	"123456789",
+		"[Group] Show - 03.mkv":			"123456789",
+		"[Group] Show - 01 [CBF43926].ass":	"subtitle",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+		}
+	}
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(dir, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	results := Verify(root, logger)
+	if len(results) != 2 {
+		t.Fatalf("Verify len = %v, want 2, got %+v", len(results), results)
+	}
+	for _, res := range results {
+		want := res.Expected == "CBF43926"
+		if res.Match() != want {
+			t.Errorf("Match for %v = %v, want %v", filepath.Base(res.Path), res.Match(), want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package classifier
 
 import (
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"github.com/ENIACore/media_library_manager/internal/metadata"
 )
 
@@ -25,14 +29,363 @@ func isBonusDir(entry *metadata.Entry) bool {
 }
 
 func isMovieDir(entry *metadata.Entry) bool {
-	return false
+	if entry.Children == nil || isSeasonDir(entry) || isSeriesDir(entry) {
+		return false
+	}
+
+	// Movie directory holds one film, either a single video or parts of it split across files or
+	// part directories, e.g. "Movie.2001.CD1.avi" and "Movie.2001.CD2.avi" or "CD1/movie.avi"
+	var videos []*metadata.Entry
+	parts := make(map[int]bool)
+	for _, child := range entry.Children {
+		if child.Type == metadata.Video && child.Bonus == "" {
+			videos = append(videos, child)
+			continue
+		}
+		if child.Children == nil || child.Part == nil {
+			continue
+		}
+		for _, video := range child.Children {
+			if video.Type == metadata.Video && video.Bonus == "" {
+				videos = append(videos, video)
+			}
+		}
+	}
+	if len(videos) <= 1 {
+		return len(videos) == 1
+	}
+
+	title := strings.Join(videos[0].Title, " ")
+	for _, video := range videos {
+		part := video.Part
+		if part == nil && video.Parent != entry {
+			part = video.Parent.Part
+		}
+		if part == nil || parts[*part] {
+			return false
+		}
+		// Part files side by side share the film's name, files inside part directories may be named anything
+		if video.Parent == entry && videos[0].Parent == entry && strings.Join(video.Title, " ") != title {
+			return false
+		}
+		parts[*part] = true
+	}
+	return true
 }
 
 func isSeasonDir(entry *metadata.Entry) bool {
+	// Season directory holds files and at most bonus or subtitle directories
+	if entry.Children == nil || entry.Height() > 2 || entry.Seasons != nil || entry.Bonus != "" {
+		return false
+	}
+
+	if entry.Season != nil && *entry.Season > 0 {
+		return true
+	}
+	for _, child := range entry.Children {
+		if child.Type == metadata.Video && child.Episode != nil && *child.Episode > 0 {
+			return true
+		}
+	}
 	return false
 }
 
 func isSeriesDir(entry *metadata.Entry) bool {
+	if entry.Children == nil {
+		return false
+	}
+
+	// Multi-season packs and complete series only need a directory holding videos,
+	// their nested season directories are often too poorly named to be recognised
+	// Other series need a season directory named as such, a directory holding episodes alone
+	// may be a single episode torrent beside unrelated ones
+	for _, child := range entry.Children {
+		if isPack(entry) && hasVideos(child) && child.Bonus == "" && !isSubtitleDir(child) {
+			return true
+		}
+		if child.Season != nil && *child.Season > 0 && isSeasonDir(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if entry names a multi-season pack or complete series
+func isPack(entry *metadata.Entry) bool {
+	return entry.Seasons != nil || entry.Complete
+}
+
+func isAlbumDir(entry *metadata.Entry) bool {
+	// Album directory holds tracks directly or through disc directories, never videos,
+	// so soundtracks and theme songs beside videos stay with them
+	if entry.Children == nil || hasVideos(entry) {
+		return false
+	}
+	if entry.Parent != nil {
+		for _, sibling := range entry.Parent.Children {
+			if sibling.Type == metadata.Video {
+				return false
+			}
+		}
+	}
+	for _, child := range entry.Children {
+		if child.Type == metadata.Audio || isDiscDir(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDiscDir(entry *metadata.Entry) bool {
+	if entry.Children == nil || entry.Height() > 1 || entry.Music.Disc == nil {
+		return false
+	}
+	for _, child := range entry.Children {
+		if child.Type == metadata.Audio {
+			return true
+		}
+	}
+	return false
+}
+
+// Links every audio track beneath entry to the album directory it belongs to, see Album
+// Tracks inside disc directories, e.g. "Album/CD2/01.flac", belong to the album holding the disc directory
+func AssignAlbums(entry *metadata.Entry) {
+	if entry.Children == nil {
+		return
+	}
+	if !isAlbumDir(entry) {
+		for _, child := range entry.Children {
+			AssignAlbums(child)
+		}
+		return
+	}
+
+	walkFiles(entry, func(file *metadata.Entry) {
+		if file.Type == metadata.Audio && (file.Parent == entry || isDiscDir(file.Parent)) {
+			file.Album = entry
+		}
+	})
+}
+
+// Gives every video inside part directories of movie directories beneath entry the part number of its
+// directory, e.g. "Movie.2001/CD2/movie-b.avi" becomes part 2, unless its name carries one
+// Such videos may be named anything, so they take title and year of the movie directory when it names the film
+func AssignParts(entry *metadata.Entry) {
+	if entry.Children == nil {
+		return
+	}
+	if !isMovieDir(entry) {
+		for _, child := range entry.Children {
+			AssignParts(child)
+		}
+		return
+	}
+
+	for _, child := range entry.Children {
+		if child.Children == nil || child.Part == nil {
+			continue
+		}
+		for _, video := range child.Children {
+			if video.Type != metadata.Video {
+				continue
+			}
+			if video.Part == nil {
+				video.Part = child.Part
+			}
+			if len(entry.Title) > 0 {
+				video.Title = entry.Title
+				video.Year = entry.Year
+			}
+		}
+	}
+}
+
+// Gives every season directory of series directories beneath root a season number, root itself
+// is never a series as it holds unrelated torrents. Directories without a usable number take it from
+// their episodes, otherwise inside packs from their position within the pack's season range,
+// e.g. the 2nd directory of "Show.S03-S05" is season 4
+func AssignSeasons(root *metadata.Entry) {
+	for _, child := range root.Children {
+		assignSeasons(child)
+	}
+}
+
+func assignSeasons(entry *metadata.Entry) {
+	if entry.Children == nil {
+		return
+	}
+	if !isSeriesDir(entry) {
+		for _, child := range entry.Children {
+			assignSeasons(child)
+		}
+		return
+	}
+
+	var seasons []*metadata.Entry
+	for _, child := range entry.Children {
+		if child.Children != nil && child.Bonus == "" && hasVideos(child) {
+			seasons = append(seasons, child)
+		}
+	}
+	sort.Slice(seasons, func(i, j int) bool {
+		return filepath.Base(seasons[i].PathInfo.Source) < filepath.Base(seasons[j].PathInfo.Source)
+	})
+
+	for i, season := range seasons {
+		if season.Season != nil && *season.Season > 0 {
+			continue
+		}
+
+		number := episodeSeason(season)
+		if number == 0 && isPack(entry) && i < len(entry.Seasons) {
+			number = entry.Seasons[i]
+		}
+		if number == 0 && isPack(entry) {
+			number = i + 1
+		}
+		if number > 0 {
+			season.Season = &number
+		}
+	}
+}
+
+// Links every subtitle beneath entry to the video it belongs to, see PairedVideo
+// Subtitles are paired with videos of the closest directory holding videos, either directly or
+// through subtitle folders such as RARBG's "Subs/<video stem>/2_English.srt" or "Subs/S01E03/"
+func PairSubtitles(entry *metadata.Entry) {
+	if entry.Children == nil {
+		return
+	}
+
+	var videos []*metadata.Entry
+	for _, child := range entry.Children {
+		if child.Type == metadata.Video {
+			videos = append(videos, child)
+		}
+	}
+	for _, child := range entry.Children {
+		switch {
+		case child.Type == metadata.Subtitle:
+			pairSubtitle(child, entry, videos)
+		case child.Children != nil && !hasVideos(child):
+			walkFiles(child, func(file *metadata.Entry) {
+				if file.Type == metadata.Subtitle {
+					pairSubtitle(file, entry, videos)
+				}
+			})
+		case child.Children != nil:
+			PairSubtitles(child)
+		}
+	}
+}
+
+// Pairs subtitle beneath dir with one of videos by, in order, shared stem, subtitle folder named
+// after a video stem, matching episode number or the only video of dir
+func pairSubtitle(subtitle *metadata.Entry, dir *metadata.Entry, videos []*metadata.Entry) {
+	if len(videos) == 0 {
+		return
+	}
+
+	name := strings.ToLower(filepath.Base(subtitle.PathInfo.Source))
+	var best *metadata.Entry
+	for _, video := range videos {
+		videoStem := strings.ToLower(stem(video.PathInfo.Source))
+		if !strings.HasPrefix(name, videoStem+".") && !strings.HasPrefix(name, videoStem+"-") {
+			continue
+		}
+		if best == nil || len(videoStem) > len(stem(best.PathInfo.Source)) {
+			best = video
+		}
+	}
+	if best != nil {
+		subtitle.PairedVideo = best
+		return
+	}
+
+	for parent := subtitle.Parent; parent != nil && parent != dir; parent = parent.Parent {
+		for _, video := range videos {
+			if strings.EqualFold(filepath.Base(parent.PathInfo.Source), stem(video.PathInfo.Source)) {
+				subtitle.PairedVideo = video
+				return
+			}
+		}
+	}
+
+	season, episode := subtitleEpisode(subtitle, dir)
+	if episode > 0 {
+		var matches []*metadata.Entry
+		for _, video := range videos {
+			info := video.Inherited()
+			if info.Episode == nil || *info.Episode != episode {
+				continue
+			}
+			if season > 0 && info.Season != nil && *info.Season > 0 && *info.Season != season {
+				continue
+			}
+			matches = append(matches, video)
+		}
+		// Several versions of one episode leave the subtitle unpaired rather than guessing
+		if len(matches) == 1 {
+			subtitle.PairedVideo = matches[0]
+		}
+		return
+	}
+
+	if len(videos) == 1 {
+		subtitle.PairedVideo = videos[0]
+	}
+}
+
+// Returns season and episode number of subtitle, taken from its folders below dir when its name has none
+func subtitleEpisode(subtitle *metadata.Entry, dir *metadata.Entry) (int, int) {
+	for entry := subtitle; entry != nil && entry != dir; entry = entry.Parent {
+		if entry.Episode == nil || *entry.Episode <= 0 {
+			continue
+		}
+		season := 0
+		if entry.Season != nil {
+			season = *entry.Season
+		}
+		return season, *entry.Episode
+	}
+	return 0, 0
+}
+
+// Calls fn for every file beneath entry
+func walkFiles(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	for _, child := range entry.Children {
+		if child.Children == nil {
+			fn(child)
+			continue
+		}
+		walkFiles(child, fn)
+	}
+}
+
+// Returns file name without directory and ext
+func stem(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// Returns season number shared by episode files of dir, 0 if not found
+func episodeSeason(dir *metadata.Entry) int {
+	for _, child := range dir.Children {
+		if child.Type == metadata.Video && child.Season != nil && *child.Season > 0 {
+			return *child.Season
+		}
+	}
+	return 0
+}
+
+// Returns true if entry is a directory with a video file at any depth
+func hasVideos(entry *metadata.Entry) bool {
+	for _, child := range entry.Children {
+		if child.Type == metadata.Video || hasVideos(child) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -51,7 +404,9 @@ Bonus Directory
 └── Subtitle File(s) (optional)
 
 Movie Directory
-├── Movie File
+├── Movie File or Part Files
+├── Part Directory(s) (optional)
+│   └── Part File
 ├── Subtitle File (optional)
 ├── Bonus File (optional)
 └── Bonus Directory (optional)
@@ -66,4 +421,9 @@ Series Directory
 ├── Season Directory(s)
 ├── Bonus Directory (optional)
 └── Subtitle Directory (optional)
+
+Album Directory
+├── Track File(s)
+└── Disc Directory(s) (optional)
+    └── Track File(s)
 */
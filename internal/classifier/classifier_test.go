@@ -1,9 +1,14 @@
 package classifier
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/parser"
 )
 
 func intPtr(i int) *int {
@@ -240,38 +245,401 @@ func TestIsBonusDir(t *testing.T) {
 func TestIsMovieDir(t *testing.T) {
 	tests := []struct{
 		name		string
-		node		metadata.Entry
+		files		[]string
+		expected	bool
 	}{
-
+		{name: "single video", files: []string{"Movie.2001.avi", "Movie.2001.srt"}, expected: true},
+		{name: "part files", files: []string{"Movie.2001.CD1.avi", "Movie.2001.CD2.avi"}, expected: true},
+		{name: "repeated part", files: []string{"Movie.2001.CD1.avi", "Movie.2001.CD1.mkv"}, expected: false},
+		{name: "different films", files: []string{"Movie.2001.CD1.avi", "Other.2001.CD2.avi"}, expected: false},
+		{name: "season", files: []string{"Show.S01E01.mkv", "Show.S01E02.mkv"}, expected: false},
 	}
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range test.files {
+				os.WriteFile(filepath.Join(dir, file), nil, 0644)
+			}
+			root, err := parser.ParseTree(dir, nil, 0, slog.Default())
+			if err != nil {
+				t.Fatalf("ParseTree returns error %v", err)
+			}
+			if isMovieDir(root) != test.expected {
+				t.Errorf("isMovieDir = %v, want %v", !test.expected, test.expected)
+			}
 		})
 	}
 }
 
 func TestIsSeasonDir(t *testing.T) {
+	episode := &metadata.Entry{
+		MediaInfo: metadata.MediaInfo{Season: intPtr(1), Episode: intPtr(1)},
+		PathInfo: metadata.PathInfo{Source: "/show/season/show s01e01.mkv", Ext: "MKV", Type: metadata.Video},
+	}
+	movie := &metadata.Entry{
+		MediaInfo: metadata.MediaInfo{Year: intPtr(2020)},
+		PathInfo: metadata.PathInfo{Source: "/movie/movie 2020.mkv", Ext: "MKV", Type: metadata.Video},
+	}
+
 	tests := []struct{
 		name		string
 		node		metadata.Entry
+		expected	bool
 	}{
-
+		{
+			name:		"directory of episodes",
+			node:		metadata.Entry{Children: []*metadata.Entry{episode}},
+			expected:	true,
+		},
+		{
+			name:		"season named directory",
+			node:		metadata.Entry{Children: []*metadata.Entry{}, MediaInfo: metadata.MediaInfo{Season: intPtr(2)}},
+			expected:	true,
+		},
+		{
+			name:		"directory of movie",
+			node:		metadata.Entry{Children: []*metadata.Entry{movie}},
+			expected:	false,
+		},
+		{
+			name:		"multi-season pack",
+			node:		metadata.Entry{Children: []*metadata.Entry{episode}, MediaInfo: metadata.MediaInfo{Season: intPtr(1), Seasons: []int{1, 2}}},
+			expected:	false,
+		},
+		{
+			name:		"file",
+			node:		*episode,
+			expected:	false,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			res := isSeasonDir(&test.node)
+			if res != test.expected {
+				t.Errorf("isSeasonDir = %v, want %v", res, test.expected)
+			}
 		})
 	}
 }
 
 func TestIsSeriesDir(t *testing.T) {
+	episode := &metadata.Entry{
+		MediaInfo: metadata.MediaInfo{Season: intPtr(1), Episode: intPtr(1)},
+		PathInfo: metadata.PathInfo{Source: "/show/season/show s01e01.mkv", Ext: "MKV", Type: metadata.Video},
+	}
+	unnumbered := &metadata.Entry{
+		PathInfo: metadata.PathInfo{Source: "/show/disc/01.mkv", Ext: "MKV", Type: metadata.Video},
+	}
+	seasonDir := &metadata.Entry{Children: []*metadata.Entry{episode}, MediaInfo: metadata.MediaInfo{Season: intPtr(1)}}
+	episodeDir := &metadata.Entry{Children: []*metadata.Entry{episode}}
+	poorlyNamedDir := &metadata.Entry{Children: []*metadata.Entry{unnumbered}}
+
 	tests := []struct{
 		name		string
 		node		metadata.Entry
+		expected	bool
 	}{
+		{
+			name:		"directory of season directories",
+			node:		metadata.Entry{Children: []*metadata.Entry{seasonDir}},
+			expected:	true,
+		},
+		{
+			name:		"multi-season pack with poorly named directories",
+			node:		metadata.Entry{Children: []*metadata.Entry{poorlyNamedDir}, MediaInfo: metadata.MediaInfo{Seasons: []int{1, 2}}},
+			expected:	true,
+		},
+		{
+			name:		"complete series with poorly named directories",
+			node:		metadata.Entry{Children: []*metadata.Entry{poorlyNamedDir}, MediaInfo: metadata.MediaInfo{Complete: true}},
+			expected:	true,
+		},
+		{
+			name:		"episode torrent directories without season names",
+			node:		metadata.Entry{Children: []*metadata.Entry{episodeDir}},
+			expected:	false,
+		},
+		{
+			name:		"poorly named directories without pack signal",
+			node:		metadata.Entry{Children: []*metadata.Entry{poorlyNamedDir}},
+			expected:	false,
+		},
+		{
+			name:		"season directory",
+			node:		*seasonDir,
+			expected:	false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := isSeriesDir(&test.node)
+			if res != test.expected {
+				t.Errorf("isSeriesDir = %v, want %v", res, test.expected)
+			}
+		})
+	}
+}
 
+func TestAssignSeasons(t *testing.T) {
+	newDir := func(name string, season *int, children ...*metadata.Entry) *metadata.Entry {
+		return &metadata.Entry{
+			Children:	children,
+			MediaInfo:	metadata.MediaInfo{Season: season},
+			PathInfo:	metadata.PathInfo{Source: "/pack/" + name, Type: metadata.Unknown},
+		}
+	}
+	newVideo := func(season *int) *metadata.Entry {
+		return &metadata.Entry{
+			MediaInfo:	metadata.MediaInfo{Season: season},
+			PathInfo:	metadata.PathInfo{Source: "/pack/dir/video.mkv", Ext: "MKV", Type: metadata.Video},
+		}
+	}
+
+	named := newDir("a named", intPtr(3), newVideo(nil))
+	fromEpisodes := newDir("b episodes", nil, newVideo(intPtr(4)))
+	fromPosition := newDir("c position", nil, newVideo(nil))
+	pack := newDir("", intPtr(3), named, fromEpisodes, fromPosition)
+	pack.Seasons = []int{3, 4, 5}
+
+	AssignSeasons(&metadata.Entry{Children: []*metadata.Entry{pack}})
+
+	tests := []struct{
+		name		string
+		node		*metadata.Entry
+		expected	int
+	}{
+		{name: "named season kept", node: named, expected: 3},
+		{name: "season from episodes", node: fromEpisodes, expected: 4},
+		{name: "season from pack range position", node: fromPosition, expected: 5},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			if test.node.Season == nil || *test.node.Season != test.expected {
+				t.Errorf("AssignSeasons Season = %v, want %v", test.node.Season, test.expected)
+			}
+		})
+	}
+}
+
+func TestAssignSeasonsMixedTorrents(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"D.Daily.E05.720p/D.Daily.E05.720p.mkv",
+		"Movie.2020.1080p/Movie.2020.1080p.mkv",
+		"Other.Movie.2019.720p/Other.Movie.2019.720p.mkv",
+		"Show.S01-S02.1080p/Disc A/Show.E01.mkv",
+		"Show.S01-S02.1080p/Disc B/Show.E01.mkv",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+	root, err := parser.ParseTree(dir, nil, 0, slog.Default())
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	AssignSeasons(root)
+
+	expected := map[string]int{
+		"D.Daily.E05.720p":				0,
+		"Movie.2020.1080p":				0,
+		"Other.Movie.2019.720p":		0,
+		"Show.S01-S02.1080p/Disc A":	1,
+		"Show.S01-S02.1080p/Disc B":	2,
+	}
+	var collect func(entry *metadata.Entry)
+	collect = func(entry *metadata.Entry) {
+		for _, child := range entry.Children {
+			if child.Children == nil {
+				continue
+			}
+			rel, _ := filepath.Rel(dir, child.PathInfo.Source)
+			if want, ok := expected[rel]; ok {
+				got := 0
+				if child.Season != nil {
+					got = *child.Season
+				}
+				if got != want {
+					t.Errorf("AssignSeasons Season for %v = %v, want %v", rel, got, want)
+				}
+			}
+			collect(child)
+		}
+	}
+	collect(root)
+}
+
+func TestPairSubtitles(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"Movie.2020.1080p/Movie.2020.1080p.mkv",
+		"Movie.2020.1080p/Subs/2_English.srt",
+		"Show.S01/Show.S01E01.1080p.WEB.mkv",
+		"Show.S01/Show.S01E02.1080p.WEB.mkv",
+		"Show.S01/Show.S01E01.1080p.WEB.en.srt",
+		"Show.S01/Show.S01E02.srt",
+		"Show.S01/Subs/Show.S01E01.1080p.WEB/3_French.srt",
+		"Show.S01/Subs/S01E02/2_English.srt",
+		"Show.S01/Show.S01E03.srt",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+	root, err := parser.ParseTree(dir, nil, 0, slog.Default())
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	PairSubtitles(root)
+
+	paired := make(map[string]string)
+	var collect func(entry *metadata.Entry)
+	collect = func(entry *metadata.Entry) {
+		if entry.PairedVideo != nil {
+			src, _ := filepath.Rel(dir, entry.PathInfo.Source)
+			paired[src] = filepath.Base(entry.PairedVideo.PathInfo.Source)
+		}
+		for _, child := range entry.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	tests := []struct{
+		name		string
+		subtitle	string
+		expected	string
+	}{
+		{name: "only video of movie dir", subtitle: "Movie.2020.1080p/Subs/2_English.srt", expected: "Movie.2020.1080p.mkv"},
+		{name: "shared stem", subtitle: "Show.S01/Show.S01E01.1080p.WEB.en.srt", expected: "Show.S01E01.1080p.WEB.mkv"},
+		{name: "episode number", subtitle: "Show.S01/Show.S01E02.srt", expected: "Show.S01E02.1080p.WEB.mkv"},
+		{name: "folder named after video stem", subtitle: "Show.S01/Subs/Show.S01E01.1080p.WEB/3_French.srt", expected: "Show.S01E01.1080p.WEB.mkv"},
+		{name: "folder named after episode", subtitle: "Show.S01/Subs/S01E02/2_English.srt", expected: "Show.S01E02.1080p.WEB.mkv"},
+		{name: "episode without video", subtitle: "Show.S01/Show.S01E03.srt", expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if paired[test.subtitle] != test.expected {
+				t.Errorf("PairSubtitles video = %v, want %v", paired[test.subtitle], test.expected)
+			}
+		})
+	}
+}
+
+func TestAssignAlbums(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"Artist - Album (2013) [FLAC]/01 - Intro.flac",
+		"Artist - Album (2013) [FLAC]/cover.jpg",
+		"Artist - Double (2010)/CD1/01 - First.flac",
+		"Artist - Double (2010)/CD2/01 - Second.flac",
+		"Movie.2020.1080p/Movie.2020.1080p.mkv",
+		"Movie.2020.1080p/Soundtrack/01 - Theme.mp3",
+		"Single.mp3",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+	root, err := parser.ParseTree(dir, nil, 0, slog.Default())
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	AssignAlbums(root)
+
+	albums := make(map[string]string)
+	var collect func(entry *metadata.Entry)
+	collect = func(entry *metadata.Entry) {
+		if entry.Album != nil {
+			src, _ := filepath.Rel(dir, entry.PathInfo.Source)
+			albums[src] = filepath.Base(entry.Album.PathInfo.Source)
+		}
+		for _, child := range entry.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	tests := []struct{
+		name		string
+		track		string
+		expected	string
+	}{
+		{name: "track of album dir", track: "Artist - Album (2013) [FLAC]/01 - Intro.flac", expected: "Artist - Album (2013) [FLAC]"},
+		{name: "track of disc dir", track: "Artist - Double (2010)/CD2/01 - Second.flac", expected: "Artist - Double (2010)"},
+		{name: "soundtrack beside video", track: "Movie.2020.1080p/Soundtrack/01 - Theme.mp3", expected: ""},
+		{name: "loose track", track: "Single.mp3", expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if albums[test.track] != test.expected {
+				t.Errorf("AssignAlbums album = %v, want %v", albums[test.track], test.expected)
+			}
+		})
+	}
+}
+
+func TestAssignParts(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"Movie.2001.DVDRip/Movie.2001.CD1.avi",
+		"Movie.2001.DVDRip/Movie.2001.CD2.avi",
+		"Other.1999.DVDRip/CD1/other-a.avi",
+		"Other.1999.DVDRip/CD2/other-b.avi",
+		"Other.1999.DVDRip/CD2/other-b.srt",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+	root, err := parser.ParseTree(dir, nil, 0, slog.Default())
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	AssignParts(root)
+
+	parts := make(map[string]int)
+	titles := make(map[string]string)
+	var collect func(entry *metadata.Entry)
+	collect = func(entry *metadata.Entry) {
+		if entry.Type == metadata.Video && entry.Part != nil {
+			src, _ := filepath.Rel(dir, entry.PathInfo.Source)
+			parts[src] = *entry.Part
+			titles[src] = strings.Join(entry.Inherited().Title, " ")
+		}
+		for _, child := range entry.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	tests := []struct{
+		name			string
+		video			string
+		expected		int
+		expectedTitle	string
+	}{
+		{name: "part file", video: "Movie.2001.DVDRip/Movie.2001.CD2.avi", expected: 2, expectedTitle: "MOVIE"},
+		{name: "first part directory", video: "Other.1999.DVDRip/CD1/other-a.avi", expected: 1, expectedTitle: "OTHER"},
+		{name: "second part directory", video: "Other.1999.DVDRip/CD2/other-b.avi", expected: 2, expectedTitle: "OTHER"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if parts[test.video] != test.expected {
+				t.Errorf("AssignParts part = %v, want %v", parts[test.video], test.expected)
+			}
+			if titles[test.video] != test.expectedTitle {
+				t.Errorf("AssignParts title = %v, want %v", titles[test.video], test.expectedTitle)
+			}
 		})
 	}
 }
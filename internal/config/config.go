@@ -10,9 +10,27 @@ type Config struct {
     MediaPath	string // Initial location of media files & dirs
     ManagerPath	string // Location of manager dir
     LibraryPath	string // Location to place processed media files & dirs in
+    MusicPath	string // Location to place processed music albums in
     DryRun		bool
+    ImportStrategy	string // How imported files are placed in library, hardlink or copy
+    VerifyChecksums	bool   // Hash files carrying a CRC32 in their name before import
+    ConvertSubtitles	bool   // Place UTF-8 copies of text subtitles in other encodings on import
+    SubtitleFormat	string // Format text subtitles are also placed in on import, srt or vtt, "" to place originals only
+    ExtractArchives	bool   // Extract RAR, ZIP and 7z sets into staging under ManagerPath and import their contents
+
+    MovieTemplate	string // text/template for movie paths relative to LibraryPath, without ext
+    EpisodeTemplate	string // text/template for episode paths relative to LibraryPath, without ext
+    DailyTemplate	string // text/template for daily show episodes identified by air date, without ext
+    MusicTemplate	string // text/template for music tracks relative to MusicPath, without ext
 }
 
+const (
+	DefaultMovieTemplate	= `Movies/{{.Title}}{{if .Year}} ({{.Year}}){{end}}/{{.Title}}{{if .Year}} ({{.Year}}){{end}}{{if .Edition}} - {{.Edition}}{{end}}{{if .Part}}-part{{.Part}}{{end}}`
+	DefaultEpisodeTemplate	= `Shows/{{.Title}}{{if .Year}} ({{.Year}}){{end}}/Season {{printf "%02d" .Season}}/{{.Title}} S{{printf "%02d" .Season}}{{.EpisodeRange}}`
+	DefaultDailyTemplate	= `Shows/{{.Title}}{{if .Year}} ({{.Year}}){{end}}/Season {{.Season}}/{{.Title}} {{.AirDate}}`
+	DefaultMusicTemplate	= `{{.Artist}}/{{.Album}}{{if .Year}} ({{.Year}}){{end}}/{{if .Disc}}{{.Disc}}-{{end}}{{if .Track}}{{printf "%02d" .Track}} - {{end}}{{.Title}}`
+)
+
 // Load reads configuration from environment variables with defaults
 var Load = sync.OnceValue(New)
 
@@ -22,7 +40,17 @@ func New() *Config {
         MediaPath:		getEnv("TORRENT_DOWNLOAD_PATH", "/mnt/RAID/qbit-data/downloads"),
 		ManagerPath:	getEnv("TORRENT_MANAGER_PATH", "/mnt/RAID/torrent-manager"),
         LibraryPath:	getEnv("MEDIA_SERVER_PATH", "/mnt/RAID/jelly/media"),
+        MusicPath:		getEnv("MUSIC_SERVER_PATH", "/mnt/RAID/jelly/music"),
         DryRun:			getEnvBool("TORRENT_MANAGER_DRY_RUN", true),
+		ImportStrategy:	getEnv("TORRENT_MANAGER_STRATEGY", "hardlink"),
+		VerifyChecksums:	getEnvBool("TORRENT_MANAGER_VERIFY_CRC", false),
+		ConvertSubtitles:	getEnvBool("TORRENT_MANAGER_SUBTITLE_UTF8", false),
+		SubtitleFormat:	getEnv("TORRENT_MANAGER_SUBTITLE_FORMAT", ""),
+		ExtractArchives:	getEnvBool("TORRENT_MANAGER_EXTRACT_ARCHIVES", false),
+		MovieTemplate:	getEnv("MOVIE_NAMING_TEMPLATE", DefaultMovieTemplate),
+		EpisodeTemplate:	getEnv("EPISODE_NAMING_TEMPLATE", DefaultEpisodeTemplate),
+		DailyTemplate:	getEnv("DAILY_NAMING_TEMPLATE", DefaultDailyTemplate),
+		MusicTemplate:	getEnv("MUSIC_NAMING_TEMPLATE", DefaultMusicTemplate),
 	}
 }
 
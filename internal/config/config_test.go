@@ -18,7 +18,17 @@ func TestNew(t *testing.T) {
 				MediaPath:   "/mnt/RAID/qbit-data/downloads",
 				ManagerPath: "/mnt/RAID/torrent-manager",
 				LibraryPath: "/mnt/RAID/jelly/media",
+				MusicPath: "/mnt/RAID/jelly/music",
 				DryRun:      true,
+				ImportStrategy: "hardlink",
+				VerifyChecksums: false,
+				ConvertSubtitles: false,
+				SubtitleFormat: "",
+				ExtractArchives: false,
+				MovieTemplate: DefaultMovieTemplate,
+				EpisodeTemplate: DefaultEpisodeTemplate,
+				DailyTemplate: DefaultDailyTemplate,
+				MusicTemplate: DefaultMusicTemplate,
 			},
 		},
 		{
@@ -27,13 +37,33 @@ func TestNew(t *testing.T) {
 				"TORRENT_DOWNLOAD_PATH":  "/custom/downloads",
 				"TORRENT_MANAGER_PATH":   "/custom/manager",
 				"MEDIA_SERVER_PATH":      "/custom/media",
+				"MUSIC_SERVER_PATH":      "/custom/music",
 				"TORRENT_MANAGER_DRY_RUN": "false",
+				"TORRENT_MANAGER_STRATEGY": "copy",
+				"TORRENT_MANAGER_VERIFY_CRC": "true",
+				"TORRENT_MANAGER_SUBTITLE_UTF8": "true",
+				"TORRENT_MANAGER_SUBTITLE_FORMAT": "srt",
+				"TORRENT_MANAGER_EXTRACT_ARCHIVES": "true",
+				"MOVIE_NAMING_TEMPLATE":   "{{.Title}}",
+				"EPISODE_NAMING_TEMPLATE": "{{.Title}} {{.Episode}}",
+				"DAILY_NAMING_TEMPLATE":   "{{.Title}} {{.AirDate}}",
+				"MUSIC_NAMING_TEMPLATE":   "{{.Artist}}/{{.Title}}",
 			},
 			expected: &Config{
 				MediaPath:   "/custom/downloads",
 				ManagerPath: "/custom/manager",
 				LibraryPath: "/custom/media",
+				MusicPath: "/custom/music",
 				DryRun:      false,
+				ImportStrategy: "copy",
+				VerifyChecksums: true,
+				ConvertSubtitles: true,
+				SubtitleFormat: "srt",
+				ExtractArchives: true,
+				MovieTemplate: "{{.Title}}",
+				EpisodeTemplate: "{{.Title}} {{.Episode}}",
+				DailyTemplate: "{{.Title}} {{.AirDate}}",
+				MusicTemplate: "{{.Artist}}/{{.Title}}",
 			},
 		},
 	}
@@ -62,9 +92,39 @@ func TestNew(t *testing.T) {
 			if cfg.LibraryPath != test.expected.LibraryPath {
 				t.Errorf("LibraryPath = %v, want %v", cfg.LibraryPath, test.expected.LibraryPath)
 			}
+			if cfg.MusicPath != test.expected.MusicPath {
+				t.Errorf("MusicPath = %v, want %v", cfg.MusicPath, test.expected.MusicPath)
+			}
 			if cfg.DryRun != test.expected.DryRun {
 				t.Errorf("DryRun = %v, want %v", cfg.DryRun, test.expected.DryRun)
 			}
+			if cfg.ImportStrategy != test.expected.ImportStrategy {
+				t.Errorf("ImportStrategy = %v, want %v", cfg.ImportStrategy, test.expected.ImportStrategy)
+			}
+			if cfg.VerifyChecksums != test.expected.VerifyChecksums {
+				t.Errorf("VerifyChecksums = %v, want %v", cfg.VerifyChecksums, test.expected.VerifyChecksums)
+			}
+			if cfg.ConvertSubtitles != test.expected.ConvertSubtitles {
+				t.Errorf("ConvertSubtitles = %v, want %v", cfg.ConvertSubtitles, test.expected.ConvertSubtitles)
+			}
+			if cfg.SubtitleFormat != test.expected.SubtitleFormat {
+				t.Errorf("SubtitleFormat = %v, want %v", cfg.SubtitleFormat, test.expected.SubtitleFormat)
+			}
+			if cfg.ExtractArchives != test.expected.ExtractArchives {
+				t.Errorf("ExtractArchives = %v, want %v", cfg.ExtractArchives, test.expected.ExtractArchives)
+			}
+			if cfg.MovieTemplate != test.expected.MovieTemplate {
+				t.Errorf("MovieTemplate = %v, want %v", cfg.MovieTemplate, test.expected.MovieTemplate)
+			}
+			if cfg.EpisodeTemplate != test.expected.EpisodeTemplate {
+				t.Errorf("EpisodeTemplate = %v, want %v", cfg.EpisodeTemplate, test.expected.EpisodeTemplate)
+			}
+			if cfg.DailyTemplate != test.expected.DailyTemplate {
+				t.Errorf("DailyTemplate = %v, want %v", cfg.DailyTemplate, test.expected.DailyTemplate)
+			}
+			if cfg.MusicTemplate != test.expected.MusicTemplate {
+				t.Errorf("MusicTemplate = %v, want %v", cfg.MusicTemplate, test.expected.MusicTemplate)
+			}
 		})
 	}
 }
@@ -192,5 +252,15 @@ func clearEnv() {
 	os.Unsetenv("TORRENT_DOWNLOAD_PATH")
 	os.Unsetenv("TORRENT_MANAGER_PATH")
 	os.Unsetenv("MEDIA_SERVER_PATH")
+	os.Unsetenv("MUSIC_SERVER_PATH")
 	os.Unsetenv("TORRENT_MANAGER_DRY_RUN")
+	os.Unsetenv("TORRENT_MANAGER_STRATEGY")
+	os.Unsetenv("TORRENT_MANAGER_VERIFY_CRC")
+	os.Unsetenv("TORRENT_MANAGER_SUBTITLE_UTF8")
+	os.Unsetenv("TORRENT_MANAGER_SUBTITLE_FORMAT")
+	os.Unsetenv("TORRENT_MANAGER_EXTRACT_ARCHIVES")
+	os.Unsetenv("MOVIE_NAMING_TEMPLATE")
+	os.Unsetenv("EPISODE_NAMING_TEMPLATE")
+	os.Unsetenv("DAILY_NAMING_TEMPLATE")
+	os.Unsetenv("MUSIC_NAMING_TEMPLATE")
 }
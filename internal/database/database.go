@@ -0,0 +1,223 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ENIACore/media_library_manager/internal/jsonl"
+)
+
+const filename = "processed.jsonl"
+
+// Strategy of records mapping the first volume of an archive to the staging directory it was extracted into
+const Extract = "extract"
+
+// Record of a single source path placed into the library
+type Record struct {
+	Source		string		`json:"source"`		// Path inside MediaPath
+	Dest		string		`json:"dest"`		// Path inside LibraryPath
+	Strategy	string		`json:"strategy"`	// How the file was placed, e.g. hardlink, copy or move
+	Session		string		`json:"session"`	// Session timestamp of the run that placed the file
+	ImportedAt	time.Time	`json:"imported_at"`
+}
+
+// Append-only store of processed records kept as JSON lines inside ManagerPath
+// Records are loaded into memory on Open, every Add is appended and synced to disk
+type DB struct {
+	mu		sync.Mutex
+	file	*jsonl.File[Record]
+	records	[]Record
+}
+
+// Opens or creates processed-state database inside managerPath
+func Open(managerPath string) (*DB, error) {
+	file, records, err := jsonl.Open[Record](managerPath, filename)
+	if err != nil {
+		return nil, fmt.Errorf("open database, %w", err)
+	}
+	return &DB{file: file, records: records}, nil
+}
+
+// Returns path of database file
+func (db *DB) Path() string {
+	return db.file.Path()
+}
+
+// Appends record to database, ImportedAt defaults to now when zero
+func (db *DB) Add(rec Record) error {
+	if rec.ImportedAt.IsZero() {
+		rec.ImportedAt = time.Now()
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.file.Append(rec); err != nil {
+		return fmt.Errorf("add record %s, %w", rec.Source, err)
+	}
+	db.records = append(db.records, rec)
+	return nil
+}
+
+// Returns true if source path has already been placed into the library
+func (db *DB) IsProcessed(source string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, rec := range db.records {
+		if rec.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns records whose source or destination is path or lies beneath path
+// Allows looking up a whole torrent directory as well as a single library file
+func (db *DB) WhereIs(path string) []Record {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var res []Record
+	for _, rec := range db.records {
+		if isWithin(rec.Source, path) || isWithin(rec.Dest, path) {
+			res = append(res, rec)
+		}
+	}
+	return res
+}
+
+// Returns records whose source is path or lies beneath path, files imported from archives extracted
+// out of path are included as their sources lie beneath the archive's staging directory
+func (db *DB) Placed(path string) []Record {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var res []Record
+	placed := db.placedFrom(path)
+	for _, rec := range db.records {
+		if placed(rec) {
+			res = append(res, rec)
+		}
+	}
+	return res
+}
+
+// Returns latest record placed at library path dest
+// Replaced library files keep their old record, so the newest record is the file currently in place
+func (db *DB) Origin(dest string) (Record, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := len(db.records) - 1; i >= 0; i-- {
+		if db.records[i].Dest == dest {
+			return db.records[i], true
+		}
+	}
+	return Record{}, false
+}
+
+// Returns records ordered oldest first, limited to the latest limit records when limit > 0
+// Only records of session are returned when session is not ""
+func (db *DB) History(session string, limit int) []Record {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var res []Record
+	for _, rec := range db.records {
+		if session == "" || rec.Session == session {
+			res = append(res, rec)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].ImportedAt.Before(res[j].ImportedAt)
+	})
+
+	if limit > 0 && len(res) > limit {
+		res = res[len(res)-limit:]
+	}
+	return res
+}
+
+// Removes records placed from path, see Placed, and returns them
+// Callers use the returned destinations to clean up library links of a removed torrent
+func (db *DB) Remove(path string) ([]Record, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var kept, removed []Record
+	placed := db.placedFrom(path)
+	for _, rec := range db.records {
+		if placed(rec) {
+			removed = append(removed, rec)
+		} else {
+			kept = append(kept, rec)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	if err := db.file.Rewrite(kept); err != nil {
+		return nil, err
+	}
+	db.records = kept
+	return removed, nil
+}
+
+// Updates destinations of records after library files moved, moves maps old to new destination
+func (db *DB) Relocate(moves map[string]string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	records := make([]Record, len(db.records))
+	changed := false
+	for i, rec := range db.records {
+		if dest, ok := moves[rec.Dest]; ok {
+			rec.Dest = dest
+			changed = true
+		}
+		records[i] = rec
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := db.file.Rewrite(records); err != nil {
+		return err
+	}
+	db.records = records
+	return nil
+}
+
+// Returns func reporting whether a record was placed from path, directly or through an extracted archive
+func (db *DB) placedFrom(path string) func(Record) bool {
+	var stagings []string
+	for _, rec := range db.records {
+		if rec.Strategy == Extract && isWithin(rec.Source, path) {
+			stagings = append(stagings, rec.Dest)
+		}
+	}
+	return func(rec Record) bool {
+		if isWithin(rec.Source, path) {
+			return true
+		}
+		for _, staging := range stagings {
+			if isWithin(rec.Source, staging) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Returns true if path equals root or is nested inside root
+func isWithin(path string, root string) bool {
+	path = filepath.Clean(path)
+	root = filepath.Clean(root)
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
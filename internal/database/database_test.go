@@ -0,0 +1,206 @@
+package database
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if len(db.History("", 0)) != 0 {
+		t.Errorf("Open on empty dir has records, want none")
+	}
+
+	err = db.Add(Record{Source: "/downloads/movie/movie.mkv", Dest: "/library/movie.mkv", Strategy: "hardlink", Session: "s1"})
+	if err != nil {
+		t.Fatalf("Add returns error %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if !reopened.IsProcessed("/downloads/movie/movie.mkv") {
+		t.Errorf("IsProcessed after reopen = false, want true")
+	}
+
+	if err := os.WriteFile(db.Path(), []byte("not json\n"), 0644); err != nil {
+		t.Fatalf("Unable to corrupt database %v", err)
+	}
+	if _, err := Open(dir); err == nil {
+		t.Errorf("Open on corrupt database returns nil error, want error")
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	db, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	db.Add(Record{Source: "/downloads/Movie.2020.1080p.mkv", Dest: "/library/Movie (2020).mkv"})
+	db.Add(Record{Source: "/downloads/Movie.2020.1080p.REPACK.mkv", Dest: "/library/Movie (2020).mkv"})
+
+	if rec, ok := db.Origin("/library/Movie (2020).mkv"); !ok || rec.Source != "/downloads/Movie.2020.1080p.REPACK.mkv" {
+		t.Errorf("Origin = %+v, %v, want latest record", rec, ok)
+	}
+	if _, ok := db.Origin("/library/Other.mkv"); ok {
+		t.Errorf("Origin of unknown dest ok = true, want false")
+	}
+}
+
+func TestWhereIs(t *testing.T) {
+	db, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	db.Add(Record{Source: "/downloads/show/e01.mkv", Dest: "/library/Show/Season 01/Show S01E01.mkv"})
+	db.Add(Record{Source: "/downloads/show/e02.mkv", Dest: "/library/Show/Season 01/Show S01E02.mkv"})
+	db.Add(Record{Source: "/downloads/showcase.mkv", Dest: "/library/Showcase.mkv"})
+
+	tests := []struct{
+		name		string
+		input		string
+		expected	int
+	}{
+		{
+			name:		"torrent directory",
+			input:		"/downloads/show",
+			expected:	2,
+		},
+		{
+			name:		"single source file",
+			input:		"/downloads/show/e02.mkv",
+			expected:	1,
+		},
+		{
+			name:		"library destination",
+			input:		"/library/Showcase.mkv",
+			expected:	1,
+		},
+		{
+			name:		"unknown path",
+			input:		"/downloads/other",
+			expected:	0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := db.WhereIs(test.input)
+			if len(res) != test.expected {
+				t.Errorf("WhereIs len = %v, want %v", len(res), test.expected)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	db, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	now := time.Now()
+	db.Add(Record{Source: "/b", Session: "s2", ImportedAt: now})
+	db.Add(Record{Source: "/a", Session: "s1", ImportedAt: now.Add(-time.Hour)})
+	db.Add(Record{Source: "/c", Session: "s2", ImportedAt: now.Add(time.Hour)})
+
+	res := db.History("", 0)
+	if len(res) != 3 || res[0].Source != "/a" || res[2].Source != "/c" {
+		t.Errorf("History = %+v, want ordered /a, /b, /c", res)
+	}
+
+	res = db.History("", 1)
+	if len(res) != 1 || res[0].Source != "/c" {
+		t.Errorf("History limit 1 = %+v, want /c", res)
+	}
+
+	res = db.History("s2", 0)
+	if len(res) != 2 {
+		t.Errorf("History session s2 len = %v, want 2", len(res))
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	db.Add(Record{Source: "/downloads/show/e01.mkv", Dest: "/library/e01.mkv"})
+	db.Add(Record{Source: "/downloads/show/e02.mkv", Dest: "/library/e02.mkv"})
+	db.Add(Record{Source: "/downloads/movie.mkv", Dest: "/library/movie.mkv"})
+
+	removed, err := db.Remove("/downloads/show")
+	if err != nil {
+		t.Fatalf("Remove returns error %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Remove len = %v, want 2", len(removed))
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if reopened.IsProcessed("/downloads/show/e01.mkv") {
+		t.Errorf("IsProcessed for removed record = true, want false")
+	}
+	if !reopened.IsProcessed("/downloads/movie.mkv") {
+		t.Errorf("IsProcessed for kept record = false, want true")
+	}
+}
+
+func TestRemoveArchive(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	db.Add(Record{Source: "/manager/staging/movie-1a2b/movie.mkv", Dest: "/library/movie.mkv", Strategy: "hardlink"})
+	db.Add(Record{Source: "/downloads/movie/movie.rar", Dest: "/manager/staging/movie-1a2b", Strategy: Extract})
+	db.Add(Record{Source: "/manager/staging/other-3c4d/other.mkv", Dest: "/library/other.mkv", Strategy: "hardlink"})
+	db.Add(Record{Source: "/downloads/other/other.rar", Dest: "/manager/staging/other-3c4d", Strategy: Extract})
+
+	if placed := db.Placed("/downloads/movie"); len(placed) != 2 {
+		t.Errorf("Placed len = %v, want 2, got %+v", len(placed), placed)
+	}
+	removed, err := db.Remove("/downloads/movie")
+	if err != nil {
+		t.Fatalf("Remove returns error %v", err)
+	}
+	if len(removed) != 2 || removed[0].Dest != "/library/movie.mkv" {
+		t.Errorf("Remove = %+v, want extracted movie and its archive", removed)
+	}
+	if !db.IsProcessed("/manager/staging/other-3c4d/other.mkv") || !db.IsProcessed("/downloads/other/other.rar") {
+		t.Errorf("Remove removed records of another archive")
+	}
+}
+
+func TestRelocate(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	db.Add(Record{Source: "/downloads/movie.mkv", Dest: "/library/movie.mkv"})
+
+	err = db.Relocate(map[string]string{"/library/movie.mkv": "/library/Movie (2020)/Movie (2020).mkv"})
+	if err != nil {
+		t.Fatalf("Relocate returns error %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	res := reopened.WhereIs("/downloads/movie.mkv")
+	if len(res) != 1 || res[0].Dest != "/library/Movie (2020)/Movie (2020).mkv" {
+		t.Errorf("WhereIs after Relocate = %+v, want relocated dest", res)
+	}
+}
@@ -0,0 +1,386 @@
+package executor
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ENIACore/media_library_manager/internal/planner"
+	"github.com/ENIACore/media_library_manager/internal/subtitle"
+)
+
+const (
+	journalDir		= "journal"
+	replacedDir		= "replaced"
+	journalExt		= ".jsonl"
+	undoneSuffix	= ".undone"
+)
+
+// Single completed action as written to the journal
+type JournalEntry struct {
+	planner.Action
+	Session	string		`json:"session"`
+	Time	time.Time	`json:"time"`
+	Backup	string		`json:"backup,omitempty"`	// Where the replaced destination was kept, "" if nothing was replaced
+}
+
+type Failure struct {
+	Action	planner.Action
+	Err		error
+}
+
+type Result struct {
+	Journal	string	// Path of journal holding completed actions, "" on dry run
+	Done	[]planner.Action
+	Failed	[]Failure
+}
+
+// Executes plan recording every completed action in the session's journal inside managerPath
+// Failed actions are collected and do not stop the remaining actions, nothing is touched on dry run
+// Destinations of replacing actions are kept beneath managerPath so the replacement can be undone
+func Execute(plan planner.Plan, managerPath string, session string, dryRun bool, logger *slog.Logger) (Result, error) {
+	log := logger.With("func", "Execute")
+	log.Info("executing plan", "root", plan.Root, "actions", len(plan.Actions))
+
+	if len(plan.Actions) == 0 {
+		return Result{}, nil
+	}
+	if dryRun {
+		for _, action := range plan.Actions {
+			log.Info("dry run, skipping action", "strategy", action.Strategy, "source", action.Source, "dest", action.Dest)
+		}
+		return Result{}, nil
+	}
+
+	dir := filepath.Join(managerPath, journalDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Result{}, fmt.Errorf("create journal dir %s, %w", dir, err)
+	}
+	res := Result{Journal: filepath.Join(dir, session+journalExt)}
+	journal, err := os.OpenFile(res.Journal, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return Result{}, fmt.Errorf("open journal %s, %w", res.Journal, err)
+	}
+	defer journal.Close()
+
+	for _, action := range plan.Actions {
+		backup := ""
+		if action.Replace {
+			backup = filepath.Join(managerPath, replacedDir, session, action.Dest)
+		}
+		if err := perform(action, backup); err != nil {
+			log.Warn("action failed", "strategy", action.Strategy, "source", action.Source, "dest", action.Dest, "error", err)
+			res.Failed = append(res.Failed, Failure{Action: action, Err: err})
+			continue
+		}
+		log.Debug("action done", "strategy", action.Strategy, "source", action.Source, "dest", action.Dest)
+
+		if err := writeJournal(journal, JournalEntry{Action: action, Session: session, Time: time.Now(), Backup: backup}); err != nil {
+			return res, err
+		}
+		res.Done = append(res.Done, action)
+
+		if action.Strategy == planner.Move {
+			pruneEmptyDirs(filepath.Dir(action.Source), plan.Root)
+		}
+	}
+
+	log.Info("finished executing plan", "done", len(res.Done), "failed", len(res.Failed))
+	return res, nil
+}
+
+// Reverses completed actions of journal newest first, emptied directories are removed up to root
+// Actions already reversed are skipped, so a partially failed undo can be repeated
+func Undo(journalPath string, root string, logger *slog.Logger) ([]planner.Action, error) {
+	log := logger.With("func", "Undo")
+	log.Info("undoing journal", "journal", journalPath)
+
+	entries, err := readJournal(journalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var undone []planner.Action
+	var errs []error
+	for i := len(entries) - 1; i >= 0; i-- {
+		action := entries[i].Action
+		ok, err := reverse(entries[i])
+		if err != nil {
+			log.Warn("undo failed", "strategy", action.Strategy, "source", action.Source, "dest", action.Dest, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			undone = append(undone, action)
+			pruneEmptyDirs(filepath.Dir(action.Dest), root)
+			if entries[i].Backup != "" {
+				// Journals live in <managerPath>/journal
+				pruneEmptyDirs(filepath.Dir(entries[i].Backup), filepath.Dir(filepath.Dir(journalPath)))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return undone, errors.Join(errs...)
+	}
+
+	if err := os.Rename(journalPath, journalPath+undoneSuffix); err != nil {
+		return undone, fmt.Errorf("mark journal %s undone, %w", journalPath, err)
+	}
+	log.Info("finished undoing journal", "undone", len(undone))
+	return undone, nil
+}
+
+// Removes destinations of linked, copied and converted actions, emptied directories are removed up to root
+// Moved destinations are the only copy of their source and are kept, missing destinations are skipped
+func Unlink(actions []planner.Action, root string, logger *slog.Logger) ([]planner.Action, error) {
+	log := logger.With("func", "Unlink")
+	log.Info("unlinking destinations", "actions", len(actions))
+
+	var removed []planner.Action
+	var errs []error
+	for _, action := range actions {
+		if action.Strategy != planner.Hardlink && action.Strategy != planner.Copy && action.Strategy != planner.Convert {
+			continue
+		}
+		if err := os.Remove(action.Dest); err != nil {
+			if !os.IsNotExist(err) {
+				log.Warn("unlink failed", "source", action.Source, "dest", action.Dest, "error", err)
+				errs = append(errs, fmt.Errorf("remove %s, %w", action.Dest, err))
+			}
+			continue
+		}
+		removed = append(removed, action)
+		pruneEmptyDirs(filepath.Dir(action.Dest), root)
+	}
+
+	log.Info("finished unlinking destinations", "removed", len(removed))
+	return removed, errors.Join(errs...)
+}
+
+// Returns path of the newest journal inside managerPath that has not been undone
+func Latest(managerPath string) (string, error) {
+	dir := filepath.Join(managerPath, journalDir)
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+journalExt))
+	if err != nil {
+		return "", fmt.Errorf("list journals %s, %w", dir, err)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no journal found in %s", dir)
+	}
+
+	// Journals are named by session timestamp which sorts chronologically
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
+}
+
+// Returns path of journal written by session inside managerPath
+func Journal(managerPath string, session string) string {
+	return filepath.Join(managerPath, journalDir, session+journalExt)
+}
+
+// Performs action, a replaced destination is first moved to backup
+func perform(action planner.Action, backup string) error {
+	if action.Replace {
+		if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+			return fmt.Errorf("create dir %s, %w", filepath.Dir(backup), err)
+		}
+		if err := os.Rename(action.Dest, backup); err != nil {
+			return fmt.Errorf("back up replaced %s, %w", action.Dest, err)
+		}
+	}
+	if err := place(action); err != nil {
+		if action.Replace {
+			os.Rename(backup, action.Dest)
+		}
+		return err
+	}
+	return nil
+}
+
+func place(action planner.Action) error {
+	if _, err := os.Lstat(action.Dest); err == nil {
+		return fmt.Errorf("destination %s already exists", action.Dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(action.Dest), 0755); err != nil {
+		return fmt.Errorf("create dir %s, %w", filepath.Dir(action.Dest), err)
+	}
+
+	switch action.Strategy {
+	case planner.Move:
+		// Rename never copies, so hardlinks of the moved file keep sharing its inode
+		if err := os.Rename(action.Source, action.Dest); err != nil {
+			return fmt.Errorf("move %s, %w", action.Source, err)
+		}
+	case planner.Hardlink:
+		if err := os.Link(action.Source, action.Dest); err != nil {
+			return fmt.Errorf("hardlink %s, %w", action.Source, err)
+		}
+	case planner.Copy:
+		if err := copyFile(action.Source, action.Dest); err != nil {
+			return err
+		}
+	case planner.Convert:
+		if err := convertFile(action.Source, action.Dest); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q", action.Strategy)
+	}
+	return nil
+}
+
+// Returns false if the action had already been reversed, a replaced destination is restored from its backup
+func reverse(entry JournalEntry) (bool, error) {
+	action := entry.Action
+	if _, err := os.Lstat(action.Dest); os.IsNotExist(err) {
+		return false, nil
+	}
+	// Once restored the backup is gone while the destination exists again
+	if entry.Backup != "" {
+		if _, err := os.Lstat(entry.Backup); os.IsNotExist(err) {
+			return false, nil
+		}
+	}
+
+	switch action.Strategy {
+	case planner.Move:
+		if _, err := os.Lstat(action.Source); err == nil {
+			return false, fmt.Errorf("restore %s, source already exists", action.Source)
+		}
+		if err := os.MkdirAll(filepath.Dir(action.Source), 0755); err != nil {
+			return false, fmt.Errorf("create dir %s, %w", filepath.Dir(action.Source), err)
+		}
+		if err := os.Rename(action.Dest, action.Source); err != nil {
+			return false, fmt.Errorf("restore %s, %w", action.Source, err)
+		}
+	case planner.Hardlink, planner.Copy, planner.Convert:
+		if err := os.Remove(action.Dest); err != nil {
+			return false, fmt.Errorf("remove %s, %w", action.Dest, err)
+		}
+	default:
+		return false, fmt.Errorf("unknown strategy %q", action.Strategy)
+	}
+
+	if entry.Backup != "" {
+		if err := os.Rename(entry.Backup, action.Dest); err != nil {
+			return false, fmt.Errorf("restore replaced %s, %w", action.Dest, err)
+		}
+	}
+	return true, nil
+}
+
+func copyFile(source string, dest string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("open %s, %w", source, err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s, %w", source, err)
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("create %s, %w", dest, err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return fmt.Errorf("copy %s, %w", source, err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return fmt.Errorf("sync %s, %w", dest, err)
+	}
+	return out.Close()
+}
+
+// Writes subtitle source converted to UTF-8 to dest, in the format of dest's ext
+func convertFile(source string, dest string) error {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("read %s, %w", source, err)
+	}
+	text, err := subtitle.Convert(data, format(source), format(dest))
+	if err != nil {
+		return fmt.Errorf("convert %s, %w", source, err)
+	}
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("create %s, %w", dest, err)
+	}
+	if _, err := out.Write(text); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return fmt.Errorf("write %s, %w", dest, err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return fmt.Errorf("sync %s, %w", dest, err)
+	}
+	return out.Close()
+}
+
+// Returns upper cased ext of path, the subtitle format it names
+func format(path string) string {
+	return strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+func writeJournal(journal *os.File, entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode journal entry %s, %w", entry.Source, err)
+	}
+	if _, err := journal.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write journal %s, %w", journal.Name(), err)
+	}
+	if err := journal.Sync(); err != nil {
+		return fmt.Errorf("sync journal %s, %w", journal.Name(), err)
+	}
+	return nil
+}
+
+func readJournal(path string) ([]JournalEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open journal %s, %w", path, err)
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("decode journal %s line %d, %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read journal %s, %w", path, err)
+	}
+	return entries, nil
+}
+
+// Removes dir and its parents while they are empty, never removing root or anything outside it
+func pruneEmptyDirs(dir string, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package executor
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/planner"
+)
+
+func TestExecuteAndUndo(t *testing.T) {
+	media := t.TempDir()
+	library := t.TempDir()
+	manager := t.TempDir()
+	logger := slog.Default()
+
+	linked := writeFile(t, filepath.Join(media, "torrent", "linked.mkv"), "linked")
+	copied := writeFile(t, filepath.Join(media, "torrent", "copied.srt"), "copied")
+	moved := writeFile(t, filepath.Join(library, "old", "moved.mkv"), "moved")
+
+	// Keep a hardlink to moved file to check move keeps it intact
+	seeding := filepath.Join(media, "seeding.mkv")
+	if err := os.Link(moved, seeding); err != nil {
+		t.Fatalf("Unable to hardlink %v, error %v", moved, err)
+	}
+
+	plan := planner.Plan{
+		Root:	library,
+		Actions: []planner.Action{
+			{Strategy: planner.Hardlink, Source: linked, Dest: filepath.Join(library, "new", "linked.mkv")},
+			{Strategy: planner.Copy, Source: copied, Dest: filepath.Join(library, "new", "copied.srt")},
+			{Strategy: planner.Move, Source: moved, Dest: filepath.Join(library, "new", "moved.mkv")},
+			{Strategy: planner.Move, Source: filepath.Join(library, "missing.mkv"), Dest: filepath.Join(library, "new", "missing.mkv")},
+		},
+	}
+
+	res, err := Execute(plan, manager, "session", true, logger)
+	if err != nil || len(res.Done) != 0 {
+		t.Fatalf("Execute dry run = %+v, %v, want nothing done", res, err)
+	}
+	if _, err := os.Stat(filepath.Join(library, "new")); !os.IsNotExist(err) {
+		t.Errorf("Execute dry run created destination dir")
+	}
+
+	res, err = Execute(plan, manager, "session", false, logger)
+	if err != nil {
+		t.Fatalf("Execute returns error %v", err)
+	}
+	if len(res.Done) != 3 || len(res.Failed) != 1 {
+		t.Errorf("Execute done = %v failed = %v, want 3 and 1", len(res.Done), len(res.Failed))
+	}
+
+	if !sameFile(t, linked, filepath.Join(library, "new", "linked.mkv")) {
+		t.Errorf("hardlinked file does not share inode with source")
+	}
+	if sameFile(t, copied, filepath.Join(library, "new", "copied.srt")) {
+		t.Errorf("copied file shares inode with source")
+	}
+	if !sameFile(t, seeding, filepath.Join(library, "new", "moved.mkv")) {
+		t.Errorf("moved file lost hardlink to seeding file")
+	}
+	if _, err := os.Stat(filepath.Join(library, "old")); !os.IsNotExist(err) {
+		t.Errorf("emptied source dir of move not removed")
+	}
+
+	latest, err := Latest(manager)
+	if err != nil || latest != Journal(manager, "session") {
+		t.Fatalf("Latest = %v, %v, want %v", latest, err, Journal(manager, "session"))
+	}
+
+	undone, err := Undo(latest, library, logger)
+	if err != nil {
+		t.Fatalf("Undo returns error %v", err)
+	}
+	if len(undone) != 3 {
+		t.Errorf("Undo len = %v, want 3", len(undone))
+	}
+	if !sameFile(t, seeding, moved) {
+		t.Errorf("restored file lost hardlink to seeding file")
+	}
+	if _, err := os.Stat(filepath.Join(library, "new")); !os.IsNotExist(err) {
+		t.Errorf("emptied destination dir not removed by undo")
+	}
+	if _, err := os.Stat(linked); err != nil {
+		t.Errorf("undo removed hardlink source %v", linked)
+	}
+	if _, err := Latest(manager); err == nil {
+		t.Errorf("Latest after undo returns nil error, want no journal left")
+	}
+}
+
+func TestExecuteReplace(t *testing.T) {
+	media := t.TempDir()
+	library := t.TempDir()
+	manager := t.TempDir()
+	logger := slog.Default()
+
+	repack := writeFile(t, filepath.Join(media, "Movie.2020.1080p.REPACK.mkv"), "repack")
+	dest := writeFile(t, filepath.Join(library, "Movie (2020)", "Movie (2020).mkv"), "original")
+
+	plan := planner.Plan{
+		Root:	media,
+		Actions: []planner.Action{
+			{Strategy: planner.Hardlink, Source: repack, Dest: dest, Replace: true},
+		},
+	}
+	res, err := Execute(plan, manager, "session", false, logger)
+	if err != nil || len(res.Done) != 1 {
+		t.Fatalf("Execute = %+v, %v, want replacing action done", res, err)
+	}
+	if !sameFile(t, repack, dest) {
+		t.Errorf("replaced destination does not share inode with source")
+	}
+
+	if _, err := Undo(Journal(manager, "session"), library, logger); err != nil {
+		t.Fatalf("Undo returns error %v", err)
+	}
+	if content, err := os.ReadFile(dest); err != nil || string(content) != "original" {
+		t.Errorf("Undo restored %q, %v, want original", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(manager, replacedDir)); !os.IsNotExist(err) {
+		t.Errorf("emptied backup dir not removed by undo")
+	}
+}
+
+func TestExecuteConvert(t *testing.T) {
+	media := t.TempDir()
+	library := t.TempDir()
+	manager := t.TempDir()
+	logger := slog.Default()
+
+	source := writeFile(t, filepath.Join(media, "Movie.2020.fr.srt"), "1\n00:00:01,000 --> 00:00:02,000\nD\xe9j\xe0 l\x92\xe9t\xe9.\n")
+	dest := filepath.Join(library, "Movie (2020)", "Movie (2020).fr.srt")
+	script := writeFile(t, filepath.Join(media, "Movie.2020.en.ass"), "[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n"+
+		"Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\i1}Hello{\\i0}\n")
+	scriptDest := filepath.Join(library, "Movie (2020)", "Movie (2020).en.srt")
+
+	plan := planner.Plan{
+		Root:	media,
+		Actions: []planner.Action{
+			{Strategy: planner.Convert, Source: source, Dest: dest},
+			{Strategy: planner.Convert, Source: script, Dest: scriptDest},
+		},
+	}
+	res, err := Execute(plan, manager, "session", false, logger)
+	if err != nil || len(res.Done) != 2 {
+		t.Fatalf("Execute = %+v, %v, want converting actions done", res, err)
+	}
+	if content, err := os.ReadFile(scriptDest); err != nil || string(content) != "1\n00:00:01,000 --> 00:00:02,000\n<i>Hello</i>\n\n" {
+		t.Errorf("converted script = %q, %v, want SRT", content, err)
+	}
+	if content, err := os.ReadFile(dest); err != nil || string(content) != "1\n00:00:01,000 --> 00:00:02,000\nDéjà l’été.\n" {
+		t.Errorf("converted destination = %q, %v, want UTF-8 text", content, err)
+	}
+	if content, _ := os.ReadFile(source); string(content) != "1\n00:00:01,000 --> 00:00:02,000\nD\xe9j\xe0 l\x92\xe9t\xe9.\n" {
+		t.Errorf("source changed to %q, want original left untouched", content)
+	}
+
+	if _, err := Undo(Journal(manager, "session"), library, logger); err != nil {
+		t.Fatalf("Undo returns error %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("Undo left converted destination %v", dest)
+	}
+}
+
+func TestUnlink(t *testing.T) {
+	media := t.TempDir()
+	library := t.TempDir()
+	logger := slog.Default()
+
+	linked := writeFile(t, filepath.Join(library, "Movies", "Movie (2020)", "Movie (2020).mkv"), "linked")
+	moved := writeFile(t, filepath.Join(library, "Movies", "Other (2021)", "Other (2021).mkv"), "moved")
+	actions := []planner.Action{
+		{Strategy: planner.Hardlink, Source: filepath.Join(media, "Movie.2020.mkv"), Dest: linked},
+		{Strategy: planner.Move, Source: filepath.Join(media, "Other.2021.mkv"), Dest: moved},
+		{Strategy: planner.Copy, Source: filepath.Join(media, "Gone.srt"), Dest: filepath.Join(library, "Gone.srt")},
+	}
+
+	removed, err := Unlink(actions, library, logger)
+	if err != nil {
+		t.Fatalf("Unlink returns error %v", err)
+	}
+	if len(removed) != 1 || removed[0].Dest != linked {
+		t.Errorf("Unlink removed = %+v, want only %v", removed, linked)
+	}
+	if _, err := os.Stat(filepath.Dir(linked)); !os.IsNotExist(err) {
+		t.Errorf("Unlink left emptied dir %v", filepath.Dir(linked))
+	}
+	if _, err := os.Stat(moved); err != nil {
+		t.Errorf("Unlink removed moved destination %v", moved)
+	}
+}
+
+func TestPruneEmptyDirs(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b", "c")
+	os.MkdirAll(nested, 0755)
+	writeFile(t, filepath.Join(root, "a", "keep"), "")
+
+	pruneEmptyDirs(nested, root)
+	if _, err := os.Stat(filepath.Join(root, "a", "b")); !os.IsNotExist(err) {
+		t.Errorf("pruneEmptyDirs left empty dir %v", filepath.Join(root, "a", "b"))
+	}
+	if _, err := os.Stat(filepath.Join(root, "a")); err != nil {
+		t.Errorf("pruneEmptyDirs removed non-empty dir %v", filepath.Join(root, "a"))
+	}
+
+	empty := t.TempDir()
+	pruneEmptyDirs(empty, empty)
+	if _, err := os.Stat(empty); err != nil {
+		t.Errorf("pruneEmptyDirs removed root %v", empty)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) string {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Unable to create dir %v, error %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to create file %v, error %v", path, err)
+	}
+	return path
+}
+
+func sameFile(t *testing.T, a string, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		t.Fatalf("Unable to stat %v, error %v", a, err)
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		t.Fatalf("Unable to stat %v, error %v", b, err)
+	}
+	return os.SameFile(infoA, infoB)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"regexp"
+	"slices"
 	"strconv"
 	"time"
 	"log/slog"
@@ -13,32 +14,95 @@ import (
 )
 
 
+// Largest spans of episodes and seasons accepted from a single range, e.g. S01E01-E03 or S01-S05
+const (
+	maxEpisodeRange	= 50
+	maxSeasonRange	= 50
+)
+
+var digitsRegex = regexp.MustCompile(`\d+`)
 
 func ExtractMedia(path string, logger *slog.Logger) metadata.MediaInfo {
 	log := logger.With("func", "ExtractMedia")
 	log.Info("extracting media info from path", "path", path)
 	filename := filepath.Base(path)	
 
-	sanitizedName := strings.Split(sanitizeName(filename), ".")
 	mediaInfo := metadata.MediaInfo{}
+	filename = extractFansub(filename, &mediaInfo)
+	sanitizedName := strings.Split(sanitizeName(filename), ".")
 	title := extractTitle(sanitizedName)
 	sanitizedName = sanitizedName[len(title):]
 
 	mediaInfo.Title = title
 	mediaInfo.Year = extractYear(sanitizedName)
 	mediaInfo.Episode = extractEpisode(sanitizedName)
+	mediaInfo.Episodes = extractEpisodes(sanitizedName)
 	mediaInfo.Season = extractSeason(sanitizedName)
+	mediaInfo.Seasons = extractSeasons(sanitizedName)
+	mediaInfo.Complete = extractComplete(sanitizedName)
+	mediaInfo.AirDate = extractAirDate(sanitizedName)
+	// Packs such as "Seasons 1-3" carry no single season pattern, take the first season of the range
+	if mediaInfo.Season == nil && mediaInfo.Seasons != nil {
+		mediaInfo.Season = &mediaInfo.Seasons[0]
+	}
 	mediaInfo.Resolution = extractResolution(sanitizedName)
 	mediaInfo.Codec = extractCodec(sanitizedName)
+	mediaInfo.DynamicRange = extractDynamicRange(sanitizedName)
+	mediaInfo.BitDepth = extractBitDepth(sanitizedName)
 	mediaInfo.Source = extractSource(sanitizedName)
 	mediaInfo.Audio = extractAudio(sanitizedName)
 	mediaInfo.Language = extractLanguage(sanitizedName)
+	mediaInfo.Languages = extractLanguages(sanitizedName)
+	mediaInfo.LanguageFlag = extractLanguageFlag(sanitizedName)
+	mediaInfo.Edition = extractEdition(sanitizedName)
+	mediaInfo.Part = extractPart(sanitizedName)
 	mediaInfo.Bonus = extractBonus(sanitizedName)
+	mediaInfo.StreamingService = extractStreamingService(sanitizedName)
+	mediaInfo.Revision = extractRevision(sanitizedName)
+	if mediaInfo.ReleaseGroup == "" {
+		mediaInfo.ReleaseGroup = extractReleaseGroup(filename, sanitizedName)
+	}
 	log.Debug("successfully extracted media info", "media-info", fmt.Sprintf("%+v", mediaInfo))
 
 	return mediaInfo
 }
 
+// Fills release group, CRC, absolute episode and version of fansub names such as
+// "[Group] Show Name - 1071v2 (1080p) [ABCD1234].mkv" from the raw, unsanitized name
+// Returns name without those tags so they are not mistaken for title segments
+func extractFansub(name string, info *metadata.MediaInfo) string {
+	for _, group := range patterns.GetFansubPatternGroups() {
+		for _, re := range group.Patterns {
+			matches := (*regexp.Regexp)(re).FindAllStringSubmatchIndex(name, -1)
+			if matches == nil {
+				continue
+			}
+			// Tags trail the name, so the last match wins
+			loc := matches[len(matches)-1]
+
+			switch group.Key {
+			case `GROUP`:
+				info.ReleaseGroup = strings.TrimSpace(name[loc[2]:loc[3]])
+			case `CRC`:
+				info.CRC = strings.ToUpper(name[loc[2]:loc[3]])
+			case `EPISODE`:
+				// Without other fansub tags " - <number>" is as likely part of a title, e.g. "Blade Runner - 2049"
+				if info.ReleaseGroup == "" && info.CRC == "" {
+					continue
+				}
+				ep, _ := strconv.Atoi(name[loc[2]:loc[3]])
+				info.AbsoluteEpisode = &ep
+				if loc[4] != -1 {
+					version, _ := strconv.Atoi(name[loc[4]:loc[5]])
+					info.Version = &version
+				}
+			}
+			name = name[:loc[0]] + " " + name[loc[1]:]
+		}
+	}
+	return name
+}
+
 // Returns title starting from left most segment
 // Extracts using segment order:
 //		-	<title>.<year (optional)>.<misc pattern>...
@@ -53,6 +117,8 @@ func extractTitle(segments []string) []string {
 		candidates := segments[i:]
 		if	parseResolution(candidates) != "" 	||
 			parseCodec(candidates) != ""		||
+			parseDynamicRange(candidates) != ""	||
+			parseBitDepth(candidates) != nil	||
 			parseSource(candidates) != "" 		||
 			parseAudio(candidates) != "" 		||
 			parseSeason(candidates) != nil 		||
@@ -61,6 +127,18 @@ func extractTitle(segments []string) []string {
 			parseSubtitleExt(candidates) != "" 	||
 			parseMisc(candidates) != "" 		||
 			parseAudioExt(candidates) != ""		||
+			parseSeasons(candidates) != nil		||
+			parseComplete(candidates)			||
+			parseAirDate(candidates) != nil		||
+			parseEdition(candidates) != ""		||
+			parseLanguageFlag(candidates) != ""	||
+			parseDiscPart(candidates) != nil	||
+			// Language codes and part numbers are common in titles, they only end a title following its year
+			(year != nil && parseLanguage(candidates) != "")	||
+			(year != nil && parsePart(candidates) != nil)		||
+			parseReleaseGroup(candidates) != ""	||
+			parseRevision(candidates) != nil	||
+			parseStreamingService(candidates) != ""	||
 			parseBonus(candidates) != "" {
 			break
 		}
@@ -83,6 +161,7 @@ func extractTitle(segments []string) []string {
 //		- <...>.<year (optional)>.<resolution, codec, source, or audio>...
 //		- <...>.<year (optional)>.<season or ep>...
 //		- <...>.<year (optional)>.<file ext>...
+//		- <...>.<year>.<language>...
 //		- <...>.<year (optional)>
 func extractYear(segments []string) *int {
 	var year *int
@@ -91,6 +170,8 @@ func extractYear(segments []string) *int {
 		candidates := segments[i:]
 		if	parseResolution(candidates) != "" 	||
 			parseCodec(candidates) != ""		||
+			parseDynamicRange(candidates) != ""	||
+			parseBitDepth(candidates) != nil	||
 			parseSource(candidates) != "" 		||
 			parseAudio(candidates) != "" 		||
 			parseSeason(candidates) != nil 		||
@@ -99,6 +180,18 @@ func extractYear(segments []string) *int {
 			parseSubtitleExt(candidates) != "" 	||
 			parseMisc(candidates) != "" 		||
 			parseAudioExt(candidates) != ""		||
+			parseSeasons(candidates) != nil		||
+			parseComplete(candidates)			||
+			parseAirDate(candidates) != nil		||
+			parseEdition(candidates) != ""		||
+			parseLanguageFlag(candidates) != ""	||
+			parseDiscPart(candidates) != nil	||
+			// Language codes and part numbers are common in titles, they only end a title following its year
+			(year != nil && parseLanguage(candidates) != "")	||
+			(year != nil && parsePart(candidates) != nil)		||
+			parseReleaseGroup(candidates) != ""	||
+			parseRevision(candidates) != nil	||
+			parseStreamingService(candidates) != ""	||
 			parseBonus(candidates) != "" {
 			return year
 		}
@@ -132,6 +225,52 @@ func extractEpisode(segments []string) *int {
 	return nil
 }
 
+// Returns every season number for multi-season packs or nil for single season names
+// Extracts without using expected segment order
+func extractSeasons(segments []string) []int {
+	for i := range segments {
+		candidates := segments[i:]
+		if seasons := parseSeasons(candidates); seasons != nil {
+			return seasons
+		}
+	}
+	return nil
+}
+
+// Returns true if any segments mark a complete series
+// Extracts without using expected segment order
+func extractComplete(segments []string) bool {
+	for i := range segments {
+		if parseComplete(segments[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns air date of daily show episodes or nil for no air date pattern
+// Extracts without using expected segment order
+func extractAirDate(segments []string) *time.Time {
+	for i := range segments {
+		if date := parseAirDate(segments[i:]); date != nil {
+			return date
+		}
+	}
+	return nil
+}
+
+// Returns every episode number for multi-episode files or nil for single episode files
+// Extracts without using expected segment order
+func extractEpisodes(segments []string) []int {
+	for i := range segments {
+		candidates := segments[i:]
+		if eps := parseEpisodes(candidates); eps != nil {
+			return eps
+		}
+	}
+	return nil
+}
+
 // Returns resolution pattern or "" for no resolution pattern
 // Extracts without using expected segment order
 func extractResolution(segments []string) string {
@@ -156,6 +295,30 @@ func extractCodec(segments []string) string {
 	return ""
 }
 
+// Returns dynamic range pattern or "" for no dynamic range pattern
+// Extracts without using expected segment order
+func extractDynamicRange(segments []string) string {
+	for i := range segments {
+		candidates := segments[i:]
+		if res := parseDynamicRange(candidates); res != "" {
+			return res
+		}
+	}
+	return ""
+}
+
+// Returns bit depth or nil for no bit depth pattern
+// Extracts without using expected segment order
+func extractBitDepth(segments []string) *int {
+	for i := range segments {
+		candidates := segments[i:]
+		if depth := parseBitDepth(candidates); depth != nil {
+			return depth
+		}
+	}
+	return nil
+}
+
 // Returns source pattern or "" for no source pattern
 // Extracts without using expected segment order
 func extractSource(segments []string) string {
@@ -190,6 +353,125 @@ func extractLanguage(segments []string) string {
 	return ""
 }
 
+// Returns ISO 639 code of every language pattern in order found or nil for no language pattern
+// Extracts without using expected segment order
+func extractLanguages(segments []string) []string {
+	var codes []string
+	for i := range segments {
+		candidates := segments[i:]
+		language := parseLanguage(candidates)
+		if language == "" {
+			continue
+		}
+		if code := patterns.LanguageCodes[language]; !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Returns MULTI or DUAL for names marking several audio languages or "" for no language flag pattern
+// Extracts without using expected segment order
+func extractLanguageFlag(segments []string) string {
+	for i := range segments {
+		candidates := segments[i:]
+		if flag := parseLanguageFlag(candidates); flag != "" {
+			return flag
+		}
+	}
+	return ""
+}
+
+// Returns edition pattern or "" for no edition pattern
+// Extracts without using expected segment order
+func extractEdition(segments []string) string {
+	for i := range segments {
+		candidates := segments[i:]
+		if edition := parseEdition(candidates); edition != "" {
+			return edition
+		}
+	}
+	return ""
+}
+
+// Returns nil if no disc or part number found
+func extractPart(segments []string) *int {
+	for i := range segments {
+		candidates := segments[i:]
+		if part := parseDiscPart(candidates); part != nil {
+			return part
+		}
+		if part := parsePart(candidates); part != nil {
+			return part
+		}
+	}
+	return nil
+}
+
+// Returns release group trailing the last "-" of the raw name, otherwise the first known release group
+// segments must not hold the title, so hyphenated titles such as "Spider-Man" are not taken for a group
+func extractReleaseGroup(name string, segments []string) string {
+	if match := (*regexp.Regexp)(patterns.GetReleaseGroupSuffixPattern()).FindStringSubmatch(name); match != nil {
+		token := strings.ToUpper(match[1])
+		i := slices.Index(segments, token)
+		// Tags such as WEB-DL or DTS-HD and dates such as 15-03-2024 also hold a "-"
+		numeric := strings.Trim(token, "0123456789") == ""
+		if i != -1 && !numeric && !isTag([]string{token}) && (i == 0 || !isTag([]string{segments[i-1] + token})) {
+			if known := parseReleaseGroup([]string{token}); known != "" {
+				return known
+			}
+			return match[1]
+		}
+	}
+
+	for i := range segments {
+		candidates := segments[i:]
+		if group := parseReleaseGroup(candidates); group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// Returns revision of releases replacing a broken one or nil for no revision pattern
+// PROPER, REPACK and RERIP count as 1 unless numbered, e.g. REPACK2, every REAL adds 1
+func extractRevision(segments []string) *int {
+	var revision *int
+	real := 0
+	for i := range segments {
+		candidates := segments[i:]
+		res := parseRevision(candidates)
+		switch {
+		case res == nil:
+		case *res == 0:
+			real++
+		case revision == nil || *res > *revision:
+			revision = res
+		}
+	}
+	if revision == nil && real == 0 {
+		return nil
+	}
+
+	res := real
+	if revision != nil {
+		res += *revision
+	}
+	return &res
+}
+
+// Returns streaming service pattern or "" for no streaming service pattern
+// Extracts without using expected segment order
+func extractStreamingService(segments []string) string {
+	for i := range segments {
+		candidates := segments[i:]
+		if service := parseStreamingService(candidates); service != "" {
+			return service
+		}
+	}
+	return ""
+}
+
 func extractBonus(segments []string) string {
 	for i := range segments {
 		candidates := segments[i:]
@@ -224,6 +506,31 @@ func parseCodec(segments []string) string {
 	return ""
 }
 
+// Helper function to return dynamic range if left most segments are a dynamic range or empty string if not
+func parseDynamicRange(segments []string) string {
+	for _, group := range patterns.GetDynamicRangePatternGroups() {
+		for _, re := range group.Patterns {
+			if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+				return group.Key
+			}
+		}
+	}
+	return ""
+}
+
+// Helper function to return bit depth if left most segments are a bit depth or nil if not
+func parseBitDepth(segments []string) *int {
+	for _, group := range patterns.GetBitDepthPatternGroups() {
+		for _, re := range group.Patterns {
+			if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+				depth, _ := strconv.Atoi(group.Key)
+				return &depth
+			}
+		}
+	}
+	return nil
+}
+
 // Helper function to return media source if left most segments are a media source or empty string if not
 func parseSource(segments []string) string {
 	for _, group := range patterns.GetSourcePatternGroups() {
@@ -313,6 +620,109 @@ func parseEpisode(segments []string) *int {
 	return nil
 }
 
+// Returns nil for MULTI EPISODE pattern not matched, otherwise every episode number in order
+// Ranges are expanded, e.g. S01E01-E03 returns 1, 2, 3
+func parseEpisodes(segments []string) []int {
+	for _, group := range patterns.GetMultiEpisodePatternGroups() {
+		for _, re := range group.Patterns {
+			match := matchSegments(segments, (*regexp.Regexp)(re))
+			if match == nil {
+				continue
+			}
+
+			switch group.Key {
+			case `LIST`:
+				var eps []int
+				for _, num := range digitsRegex.FindAllString(match[1], -1) {
+					ep, _ := strconv.Atoi(num)
+					eps = append(eps, ep)
+				}
+				return eps
+			case `RANGE`:
+				first, _ := strconv.Atoi(match[1])
+				last, _ := strconv.Atoi(match[2])
+				if last <= first || last-first > maxEpisodeRange {
+					continue
+				}
+				eps := make([]int, 0, last-first+1)
+				for ep := first; ep <= last; ep++ {
+					eps = append(eps, ep)
+				}
+				return eps
+			}
+		}
+	}
+	return nil
+}
+
+// Returns nil for MULTI SEASON pattern not matched, otherwise every season number in order
+func parseSeasons(segments []string) []int {
+	for _, group := range patterns.GetMultiSeasonPatternGroups() {
+		for _, re := range group.Patterns {
+			match := matchSegments(segments, (*regexp.Regexp)(re))
+			if match == nil {
+				continue
+			}
+
+			first, _ := strconv.Atoi(match[1])
+			last, _ := strconv.Atoi(match[2])
+			if last <= first || last-first > maxSeasonRange {
+				continue
+			}
+			seasons := make([]int, 0, last-first+1)
+			for season := first; season <= last; season++ {
+				seasons = append(seasons, season)
+			}
+			return seasons
+		}
+	}
+	return nil
+}
+
+// Returns true if left most segments mark a complete series
+func parseComplete(segments []string) bool {
+	for _, re := range patterns.GetCompleteSeriesPatterns() {
+		if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns nil for AIR DATE pattern not matched or not a real date, otherwise the air date
+func parseAirDate(segments []string) *time.Time {
+	for _, group := range patterns.GetAirDatePatternGroups() {
+		for _, re := range group.Patterns {
+			match := matchSegments(segments, (*regexp.Regexp)(re))
+			if match == nil {
+				continue
+			}
+
+			var year, month, day int
+			switch group.Key {
+			case `YMD`:
+				year, _ = strconv.Atoi(match[1])
+				month, _ = strconv.Atoi(match[2])
+				day, _ = strconv.Atoi(match[3])
+			case `DMY`:
+				day, _ = strconv.Atoi(match[1])
+				month, _ = strconv.Atoi(match[2])
+				year, _ = strconv.Atoi(match[3])
+			}
+			if parseYear(strconv.Itoa(year)) == nil {
+				continue
+			}
+			// time.Date normalizes out of range values, e.g. 02.30 becomes 03.01
+			date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+			if date.Month() != time.Month(month) || date.Day() != day {
+				continue
+			}
+			return &date
+		}
+	}
+	return nil
+}
+
 func parseLanguage(segments []string) string {
 	for _, group := range patterns.GetLanguagePatternGroups() {
 		for _, re := range group.Patterns {
@@ -325,6 +735,119 @@ func parseLanguage(segments []string) string {
 	return ""
 }
 
+// Helper function to return edition if left most segments are an edition or empty string if not
+func parseEdition(segments []string) string {
+	for _, group := range patterns.GetEditionPatternGroups() {
+		for _, re := range group.Patterns {
+			if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+				return group.Key
+			}
+		}
+	}
+	return ""
+}
+
+// Returns nil for CD or DISC pattern not matched, otherwise the part number
+func parseDiscPart(segments []string) *int {
+	return parsePartNumber(segments, patterns.GetDiscPatterns())
+}
+
+// Returns nil for PART pattern not matched, otherwise the part number
+func parsePart(segments []string) *int {
+	return parsePartNumber(segments, patterns.GetPartPatterns())
+}
+
+// Returns number of the first of res matching left most segments, nil if none match
+func parsePartNumber(segments []string, res []*patterns.CompiledPattern) *int {
+	for _, re := range res {
+		match := matchSegments(segments, (*regexp.Regexp)(re))
+		if match == nil {
+			continue
+		}
+		if part, err := strconv.Atoi(match[1]); err == nil && part > 0 {
+			return &part
+		}
+	}
+	return nil
+}
+
+// Helper function to return release group if left most segments are a known release group or empty string if not
+func parseReleaseGroup(segments []string) string {
+	for _, group := range patterns.GetReleaseGroupPatternGroups() {
+		for _, re := range group.Patterns {
+			if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+				return group.Key
+			}
+		}
+	}
+	return ""
+}
+
+// Helper function to return streaming service if left most segments are a streaming service or empty string if not
+func parseStreamingService(segments []string) string {
+	for _, group := range patterns.GetStreamingServicePatternGroups() {
+		for _, re := range group.Patterns {
+			if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+				return group.Key
+			}
+		}
+	}
+	return ""
+}
+
+// Returns nil for REVISION pattern not matched, 0 for REAL, otherwise revision number
+func parseRevision(segments []string) *int {
+	for _, group := range patterns.GetRevisionPatternGroups() {
+		for _, re := range group.Patterns {
+			match := matchSegments(segments, (*regexp.Regexp)(re))
+			if match == nil {
+				continue
+			}
+
+			revision := 0
+			if group.Key == `REVISION` {
+				revision = 1
+				if n, err := strconv.Atoi(match[1]); err == nil && n > 0 {
+					revision = n
+				}
+			}
+			return &revision
+		}
+	}
+	return nil
+}
+
+// Returns true if left most segments are a quality, series, edition, language or misc tag
+func isTag(segments []string) bool {
+	return	parseResolution(segments) != ""			||
+			parseCodec(segments) != ""				||
+			parseDynamicRange(segments) != ""		||
+			parseBitDepth(segments) != nil			||
+			parseSource(segments) != ""				||
+			parseAudio(segments) != ""				||
+			parseSeason(segments) != nil			||
+			parseEpisode(segments) != nil			||
+			parseEdition(segments) != ""			||
+			parseLanguage(segments) != ""			||
+			parseLanguageFlag(segments) != ""		||
+			parseStreamingService(segments) != ""	||
+			parseRevision(segments) != nil			||
+			parseDiscPart(segments) != nil			||
+			parsePart(segments) != nil				||
+			parseMisc(segments) != ""
+}
+
+func parseLanguageFlag(segments []string) string {
+	for _, group := range patterns.GetLanguageFlagPatternGroups() {
+		for _, re := range group.Patterns {
+			if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+				return group.Key
+			}
+		}
+	}
+	return ""
+}
+
 func parseMisc(segments []string) string {
 	for _, re := range patterns.GetMiscPatterns() {
 		if match := matchSegments(segments, (*regexp.Regexp)(re)); match != nil {
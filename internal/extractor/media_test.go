@@ -6,12 +6,18 @@ import (
 	"log/slog"
 	"github.com/ENIACore/media_library_manager/internal/metadata"
 	"reflect"
+	"time"
 )
 
 func intPtr(i int) *int {
 	return &i
 }
 
+func datePtr(year int, month time.Month, day int) *time.Time {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
 func TestExtractMedia(t *testing.T) {
 	logger := slog.Default()
 	tests := []struct{
@@ -35,6 +41,158 @@ func TestExtractMedia(t *testing.T) {
 				Source: "BLURAY",
 				Audio: "ATMOS",
 				Language: "ENGLISH",
+				Languages: []string{"en"},
+			},
+		},
+		{
+			name:		"multi-language release",
+			input:		"/parent/Movie.2020.ITA.ENG.MULTI.1080p.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"MOVIE",
+				},
+				Year: intPtr(2020),
+				Resolution: "1080P",
+				Language: "ITALIAN",
+				Languages: []string{"it", "en"},
+				LanguageFlag: "MULTI",
+			},
+		},
+		{
+			name:		"multi-episode file",
+			input:		"/parent/Show.S01E01-E02.720p.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"SHOW",
+				},
+				Season: intPtr(1),
+				Episode: intPtr(1),
+				Episodes: []int{1, 2},
+				Resolution: "720P",
+			},
+		},
+		{
+			name:		"multi-season pack",
+			input:		"/parent/Show.S01-S03.1080p",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"SHOW",
+				},
+				Season: intPtr(1),
+				Seasons: []int{1, 2, 3},
+				Resolution: "1080P",
+			},
+		},
+		{
+			name:		"complete series pack",
+			input:		"/parent/Show.Complete.Series.720p",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"SHOW",
+				},
+				Complete: true,
+				Resolution: "720P",
+			},
+		},
+		{
+			name:		"daily show",
+			input:		"/parent/Show.2024.03.15.Guest.Name.720p.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"SHOW",
+				},
+				AirDate: datePtr(2024, time.March, 15),
+				Resolution: "720P",
+			},
+		},
+		{
+			name:		"daily show with day first",
+			input:		"/parent/Show 15-03-2024.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"SHOW",
+				},
+				AirDate: datePtr(2024, time.March, 15),
+			},
+		},
+		{
+			name:		"movie edition",
+			input:		"/parent/Blade.Runner.1982.Directors.Cut.2160p.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"BLADE",
+					"RUNNER",
+				},
+				Year: intPtr(1982),
+				Resolution: "4K",
+				Edition: "DIRECTORS_CUT",
+			},
+		},
+		{
+			name:		"dynamic range and bit depth",
+			input:		"/parent/Movie.2021.2160p.DV.HDR10.10bit.x265.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"MOVIE",
+				},
+				Year: intPtr(2021),
+				Resolution: "4K",
+				Codec: "X265",
+				DynamicRange: "DV",
+				BitDepth: intPtr(10),
+			},
+		},
+		{
+			name:		"scene release",
+			input:		"/parent/Movie.2020.1080p.AMZN.WEB-DL.DDP.5.1.H.264-NTb.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"MOVIE",
+				},
+				Year: intPtr(2020),
+				Resolution: "1080P",
+				Codec: "X264",
+				Source: "WEB-DL",
+				Audio: "DD+",
+				ReleaseGroup: "NTB",
+				StreamingService: "AMZN",
+			},
+		},
+		{
+			name:		"anniversary release tag",
+			input:		"/parent/Movie.2001.ANNIVERSARY.1080p.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"MOVIE",
+				},
+				Year: intPtr(2001),
+				Resolution: "1080P",
+			},
+		},
+		{
+			name:		"collectors release tag",
+			input:		"/parent/Movie.2001.COLLECTORS.1080p.mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"MOVIE",
+				},
+				Year: intPtr(2001),
+				Resolution: "1080P",
+			},
+		},
+		{
+			name:		"anime fansub",
+			input:		"/parent/[SubsPlease] Show Name - 1071v2 (1080p) [ABCD1234].mkv",
+			expected:	metadata.MediaInfo{
+				Title: []string{
+					"SHOW",
+					"NAME",
+				},
+				AbsoluteEpisode: intPtr(1071),
+				Version: intPtr(2),
+				CRC: "ABCD1234",
+				ReleaseGroup: "SubsPlease",
+				Resolution: "1080P",
 			},
 		},
 	}
@@ -49,6 +207,48 @@ func TestExtractMedia(t *testing.T) {
 	}
 }
 
+func TestExtractFansub(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		string
+		expected	metadata.MediaInfo
+		remaining	string
+	}{
+		{
+			name:		"group, episode and crc",
+			input:		"[Erai-raws] Show - 05 [720p][abcd1234].mkv",
+			expected:	metadata.MediaInfo{ReleaseGroup: "Erai-raws", AbsoluteEpisode: intPtr(5), CRC: "ABCD1234"},
+			remaining:	"  Show [720p] .mkv",
+		},
+		{
+			name:		"episode with version",
+			input:		"[Group] Show - 12v3.mkv",
+			expected:	metadata.MediaInfo{ReleaseGroup: "Group", AbsoluteEpisode: intPtr(12), Version: intPtr(3)},
+			remaining:	"  Show mkv",
+		},
+		{
+			name:		"dash number without fansub tags",
+			input:		"Blade Runner - 2049 (1080p).mkv",
+			expected:	metadata.MediaInfo{},
+			remaining:	"Blade Runner - 2049 (1080p).mkv",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var info metadata.MediaInfo
+			remaining := extractFansub(test.input, &info)
+
+			if !reflect.DeepEqual(info, test.expected) {
+				t.Errorf("extractFansub info = %+v, want %+v", info, test.expected)
+			}
+			if remaining != test.remaining {
+				t.Errorf("extractFansub = %q, want %q", remaining, test.remaining)
+			}
+		})
+	}
+}
+
 func TestExtractTitle(t *testing.T) {
 	tests := []struct {
 		name			string
@@ -394,6 +594,192 @@ func TestExtractEpisode(t *testing.T) {
 	}
 }
 
+func TestExtractEpisodes(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	[]int
+	}{
+		{
+			name:		"concatenated episodes",
+			input:		[]string{
+				"S01E01E02",
+				"1080P",
+				"MKV",
+			},
+			expected: 	[]int{1, 2},
+		},
+		{
+			name:		"episode range",
+			input:		[]string{
+				"S01E01",
+				"E03",
+				"1080P",
+				"MKV",
+			},
+			expected: 	[]int{1, 2, 3},
+		},
+		{
+			name:		"single episode",
+			input:		[]string{
+				"S01E01",
+				"1080P",
+				"MKV",
+			},
+			expected: 	nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eps := extractEpisodes(test.input)
+
+			if !reflect.DeepEqual(eps, test.expected) {
+    			t.Errorf("extractEpisodes = %v, want %v", eps, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractSeasons(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	[]int
+	}{
+		{
+			name:		"season range",
+			input:		[]string{
+				"S01",
+				"S03",
+				"1080P",
+			},
+			expected: 	[]int{1, 2, 3},
+		},
+		{
+			name:		"seasons word range",
+			input:		[]string{
+				"SEASONS",
+				"2",
+				"4",
+				"720P",
+			},
+			expected: 	[]int{2, 3, 4},
+		},
+		{
+			name:		"single season",
+			input:		[]string{
+				"S01",
+				"1080P",
+			},
+			expected: 	nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			seasons := extractSeasons(test.input)
+
+			if !reflect.DeepEqual(seasons, test.expected) {
+    			t.Errorf("extractSeasons = %v, want %v", seasons, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractComplete(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	bool
+	}{
+		{
+			name:		"complete series",
+			input:		[]string{
+				"COMPLETE",
+				"SERIES",
+				"1080P",
+			},
+			expected: 	true,
+		},
+		{
+			name:		"complete season",
+			input:		[]string{
+				"S01",
+				"COMPLETE",
+				"1080P",
+			},
+			expected: 	false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			complete := extractComplete(test.input)
+
+			if complete != test.expected {
+    			t.Errorf("extractComplete = %v, want %v", complete, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractAirDate(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	*time.Time
+	}{
+		{
+			name:		"year first",
+			input:		[]string{
+				"2024",
+				"03",
+				"15",
+				"720P",
+			},
+			expected: 	datePtr(2024, time.March, 15),
+		},
+		{
+			name:		"day first",
+			input:		[]string{
+				"GUEST",
+				"01",
+				"12",
+				"2023",
+			},
+			expected: 	datePtr(2023, time.December, 1),
+		},
+		{
+			name:		"invalid date",
+			input:		[]string{
+				"2024",
+				"02",
+				"30",
+			},
+			expected: 	nil,
+		},
+		{
+			name:		"year only",
+			input:		[]string{
+				"2024",
+				"1080P",
+			},
+			expected: 	nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			date := extractAirDate(test.input)
+
+			if !reflect.DeepEqual(date, test.expected) {
+    			t.Errorf("extractAirDate = %v, want %v", date, test.expected)
+			}
+		})
+	}
+}
+
 func TestExtractResolution(t *testing.T) {
 	tests := []struct{
 		name		string
@@ -468,6 +854,89 @@ func TestExtractCodec(t *testing.T) {
 	}
 }
 
+func TestExtractDynamicRange(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	string
+	}{
+		{
+			name:		"hdr10 plus",
+			input:		[]string{
+				"2160P",
+				"HDR10PLUS",
+			},
+			expected: 	"HDR10+",
+		},
+		{
+			name:		"dolby vision with separator",
+			input:		[]string{
+				"DOLBY",
+				"VISION",
+			},
+			expected: 	"DV",
+		},
+		{
+			name:		"no dynamic range",
+			input:		[]string{
+				"1080P",
+			},
+			expected: 	"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := extractDynamicRange(test.input)
+
+			if res != test.expected {
+    			t.Errorf("extractDynamicRange = %v, want %v", res, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractBitDepth(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	*int
+	}{
+		{
+			name:		"bit depth with separator",
+			input:		[]string{
+				"10",
+				"BIT",
+			},
+			expected: 	intPtr(10),
+		},
+		{
+			name:		"anime hi10p",
+			input:		[]string{
+				"HI10P",
+			},
+			expected: 	intPtr(10),
+		},
+		{
+			name:		"no bit depth",
+			input:		[]string{
+				"X264",
+			},
+			expected: 	nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := extractBitDepth(test.input)
+
+			if !reflect.DeepEqual(res, test.expected) {
+    			t.Errorf("extractBitDepth = %v, want %v", res, test.expected)
+			}
+		})
+	}
+}
+
 func TestExtractSource(t *testing.T) {
 	tests := []struct{
 		name		string
@@ -585,6 +1054,317 @@ func TestExtractLanguage(t *testing.T) {
 	}
 }
 
+func TestExtractLanguages(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	[]string
+	}{
+		{
+			name:		"several languages",
+			input:		[]string{"MOVIE", "2020", "ITA", "ENG", "1080P"},
+			expected:	[]string{"it", "en"},
+		},
+		{
+			name:		"duplicate language",
+			input:		[]string{"MOVIE", "2020", "ENG", "ENGLISH", "1080P"},
+			expected:	[]string{"en"},
+		},
+		{
+			name:		"missing language",
+			input:		[]string{"MOVIE", "2020", "1080P"},
+			expected:	nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			languages := extractLanguages(test.input)
+			if !reflect.DeepEqual(languages, test.expected) {
+				t.Errorf("extractLanguages = %v, want %v", languages, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractLanguageFlag(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	string
+	}{
+		{
+			name:		"multi",
+			input:		[]string{"MOVIE", "2020", "MULTI", "1080P"},
+			expected:	"MULTI",
+		},
+		{
+			name:		"dual audio",
+			input:		[]string{"MOVIE", "2020", "DUAL", "AUDIO", "1080P"},
+			expected:	"DUAL",
+		},
+		{
+			name:		"missing flag",
+			input:		[]string{"MOVIE", "2020", "ENG", "1080P"},
+			expected:	"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flag := extractLanguageFlag(test.input)
+			if flag != test.expected {
+				t.Errorf("extractLanguageFlag = %v, want %v", flag, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractEdition(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	string
+	}{
+		{
+			name:		"multi segment edition",
+			input:		[]string{
+				"EXTENDED",
+				"EDITION",
+				"1080P",
+			},
+			expected: 	"EXTENDED",
+		},
+		{
+			name:		"edition after quality",
+			input:		[]string{
+				"1080P",
+				"IMAX",
+			},
+			expected: 	"IMAX",
+		},
+		{
+			name:		"limited edition",
+			input:		[]string{
+				"LIMITED",
+				"EDITION",
+				"1080P",
+			},
+			expected: 	"LIMITED_EDITION",
+		},
+		{
+			name:		"limited release tag",
+			input:		[]string{
+				"LIMITED",
+				"1080P",
+				"BLURAY",
+			},
+			expected: 	"",
+		},
+		{
+			name:		"no edition",
+			input:		[]string{
+				"1080P",
+				"X264",
+			},
+			expected: 	"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edition := extractEdition(test.input)
+
+			if edition != test.expected {
+    			t.Errorf("extractEdition = %v, want %v", edition, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractPart(t *testing.T) {
+	logger := slog.Default()
+	tests := []struct{
+		name			string
+		input			string
+		expectedTitle	[]string
+		expectedPart	*int
+	}{
+		{
+			name:			"cd after year",
+			input:			"/parent/Movie.2001.CD1.avi",
+			expectedTitle:	[]string{"MOVIE"},
+			expectedPart:	intPtr(1),
+		},
+		{
+			name:			"disc without year",
+			input:			"/parent/Movie Disc 2.avi",
+			expectedTitle:	[]string{"MOVIE"},
+			expectedPart:	intPtr(2),
+		},
+		{
+			name:			"part after year",
+			input:			"/parent/Movie.2001.DVDRip.XviD-GRP.Part2.avi",
+			expectedTitle:	[]string{"MOVIE"},
+			expectedPart:	intPtr(2),
+		},
+		{
+			name:			"part within title",
+			input:			"/parent/Harry.Potter.and.the.Deathly.Hallows.Part.1.2010.1080p.mkv",
+			expectedTitle:	[]string{"HARRY", "POTTER", "AND", "THE", "DEATHLY", "HALLOWS", "PART", "1"},
+			expectedPart:	nil,
+		},
+		{
+			name:			"no part",
+			input:			"/parent/Movie.2001.avi",
+			expectedTitle:	[]string{"MOVIE"},
+			expectedPart:	nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := ExtractMedia(test.input, logger)
+			if !reflect.DeepEqual(info.Title, test.expectedTitle) {
+				t.Errorf("ExtractMedia title = %v, want %v", info.Title, test.expectedTitle)
+			}
+			if !reflect.DeepEqual(info.Part, test.expectedPart) {
+				t.Errorf("ExtractMedia part = %v, want %v", info.Part, test.expectedPart)
+			}
+		})
+	}
+}
+
+func TestExtractStreamingService(t *testing.T) {
+	logger := slog.Default()
+	tests := []struct{
+		name			string
+		input			string
+		expectedTitle	[]string
+		expected		string
+	}{
+		{
+			name:			"service tag",
+			input:			"/parent/Show.S01E01.1080p.NF.WEB-DL.DDP5.1.x264-GRP.mkv",
+			expectedTitle:	[]string{"SHOW"},
+			expected:		"NF",
+		},
+		{
+			name:			"title starting with disney",
+			input:			"/parent/Disney.Gallery.S01E01.1080p.mkv",
+			expectedTitle:	[]string{"DISNEY", "GALLERY"},
+			expected:		"",
+		},
+		{
+			name:			"title containing max",
+			input:			"/parent/Mad.Max.Fury.Road.2015.1080p.mkv",
+			expectedTitle:	[]string{"MAD", "MAX", "FURY", "ROAD"},
+			expected:		"",
+		},
+		{
+			name:			"title starting with amazon",
+			input:			"/parent/Amazon.Women.on.the.Moon.1987.1080p.mkv",
+			expectedTitle:	[]string{"AMAZON", "WOMEN", "ON", "THE", "MOON"},
+			expected:		"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := ExtractMedia(test.input, logger)
+			if !reflect.DeepEqual(info.Title, test.expectedTitle) {
+				t.Errorf("ExtractMedia title = %v, want %v", info.Title, test.expectedTitle)
+			}
+			if info.StreamingService != test.expected {
+				t.Errorf("ExtractMedia streaming service = %v, want %v", info.StreamingService, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractReleaseGroup(t *testing.T) {
+	tests := []struct{
+		name		string
+		raw			string
+		input		[]string
+		expected	string
+	}{
+		{
+			name:		"trailing group",
+			raw:		"Movie.2020.1080p.x264-Grp.mkv",
+			input:		[]string{"2020", "1080P", "X264", "GRP", "MKV"},
+			expected:	"Grp",
+		},
+		{
+			name:		"trailing tag is not a group",
+			raw:		"Movie.2020.1080p.WEB-DL.mkv",
+			input:		[]string{"2020", "1080P", "WEB", "DL", "MKV"},
+			expected:	"",
+		},
+		{
+			name:		"hyphenated title is not a group",
+			raw:		"Spider-Man.mkv",
+			input:		[]string{"MKV"},
+			expected:	"",
+		},
+		{
+			name:		"known group without dash",
+			raw:		"Movie.2020.1080p.RARBG.mkv",
+			input:		[]string{"2020", "1080P", "RARBG", "MKV"},
+			expected:	"RARBG",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			group := extractReleaseGroup(test.raw, test.input)
+
+			if group != test.expected {
+    			t.Errorf("extractReleaseGroup = %v, want %v", group, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractRevision(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	*int
+	}{
+		{
+			name:		"repack",
+			input:		[]string{"REPACK", "1080P"},
+			expected:	intPtr(1),
+		},
+		{
+			name:		"numbered repack",
+			input:		[]string{"1080P", "REPACK2"},
+			expected:	intPtr(2),
+		},
+		{
+			name:		"real proper",
+			input:		[]string{"REAL", "PROPER", "720P"},
+			expected:	intPtr(2),
+		},
+		{
+			name:		"no revision",
+			input:		[]string{"720P"},
+			expected:	nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revision := extractRevision(test.input)
+
+			if !reflect.DeepEqual(revision, test.expected) {
+    			t.Errorf("extractRevision = %v, want %v", revision, test.expected)
+			}
+		})
+	}
+}
+
 func TestExtractBonus(t *testing.T) {
 	tests := []struct{
 		name		string
@@ -958,6 +1738,49 @@ func TestParseEpisode(t *testing.T) {
 }
 
 
+func TestParseEpisodes(t *testing.T) {
+	tests := []struct {
+		name			string
+		input			[]string
+		expected		[]int
+	}{
+		{
+			name:		"three concatenated episodes",
+			input:		[]string{
+				"S02E04E05E06",
+				"MKV",
+			},
+			expected: []int{4, 5, 6},
+		},
+		{
+			name:		"backwards range",
+			input:		[]string{
+				"S01E03",
+				"E01",
+				"MKV",
+			},
+			expected: nil,
+		},
+		{
+			name:		"single episode",
+			input:		[]string{
+				"S01E03",
+				"MKV",
+			},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eps := parseEpisodes(test.input)
+			if !reflect.DeepEqual(eps, test.expected) {
+    			t.Errorf("parseEpisodes = %v, want %v", eps, test.expected)
+			}
+		})
+	}
+}
+
 func TestParseLanguage(t *testing.T) {
 	tests := []struct {
 		name			string
@@ -0,0 +1,188 @@
+package extractor
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/patterns"
+)
+
+var (
+	// "01 - Title", "01. Title", "01 Title" or "1-01 Title" with disc
+	trackRegex			= regexp.MustCompile(`^(?:(\d{1,2})[-.])?(\d{1,3})(?:\s*[-.]\s*|\s+)(.+)$`)
+	// "Artist - 01 - Title"
+	artistTrackRegex	= regexp.MustCompile(`^(.+?)\s+-\s+(\d{1,3})\s+-\s+(.+)$`)
+	bracketRegex		= regexp.MustCompile(`\s*[\[(]([^\])]*)[\])]`)
+	yearRegex			= regexp.MustCompile(`^(?:19|20)\d{2}$`)
+)
+
+// Extracts artist, album, track and disc from audio file names or from names of directories holding them
+// e.g. "Artist - Album (2013) [FLAC]", "Artist-Album-WEB-2013-GRP", "CD2" or "1-05 - Title.flac"
+func ExtractMusic(path string, logger *slog.Logger) metadata.MusicInfo {
+	log := logger.With("func", "ExtractMusic")
+	log.Info("extracting music info from path", "path", path)
+
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+
+	// Only a real extension marks a track, album names commonly end in format tags such as "[FLAC]"
+	var musicInfo metadata.MusicInfo
+	if parseAudioExt([]string{strings.ToUpper(strings.TrimPrefix(ext, "."))}) != "" {
+		musicInfo = extractTrack(strings.TrimSuffix(name, ext))
+	} else {
+		musicInfo = extractAlbum(name)
+	}
+
+	log.Debug("successfully extracted music info", "music-info", fmt.Sprintf("%+v", musicInfo))
+	return musicInfo
+}
+
+// Returns track number, disc, title and artist of audio file name without ext
+func extractTrack(name string) metadata.MusicInfo {
+	name, scene := normalizeMusicName(name)
+
+	var info metadata.MusicInfo
+	if match := artistTrackRegex.FindStringSubmatch(name); match != nil {
+		info.Artist = match[1]
+		info.Track = atoi(match[2])
+		info.Title = match[3]
+		return info
+	}
+
+	match := trackRegex.FindStringSubmatch(name)
+	if match == nil {
+		info.Title = name
+		return info
+	}
+	if match[1] != "" {
+		info.Disc = atoi(match[1])
+	}
+	info.Track = atoi(match[2])
+	info.Title = match[3]
+
+	// Scene tracks are "01-artist-title", others may be "01 - Artist - Title"
+	separator := " - "
+	if scene {
+		separator = "-"
+	}
+	if artist, title, ok := strings.Cut(info.Title, separator); ok {
+		info.Artist = strings.TrimSpace(artist)
+		info.Title = strings.TrimSpace(title)
+	}
+	return info
+}
+
+// Returns artist, album, year and disc of directory name, disc directories such as "CD2" only give the disc
+func extractAlbum(name string) metadata.MusicInfo {
+	var info metadata.MusicInfo
+	if disc := parseDisc(name); disc != nil {
+		info.Disc = disc
+		return info
+	}
+	name, scene := normalizeMusicName(name)
+
+	// Bracketed years, discs and format tags are dropped, anything else such as "(Deluxe Edition)" is kept
+	name = bracketRegex.ReplaceAllStringFunc(name, func(group string) string {
+		content := strings.TrimSpace(bracketRegex.FindStringSubmatch(group)[1])
+		if kept := parseAlbumTag(content, &info); kept {
+			return group
+		}
+		return ""
+	})
+
+	separator := " - "
+	if scene {
+		separator = "-"
+	}
+	var parts []string
+	for _, part := range strings.Split(name, separator) {
+		part = strings.TrimSpace(part)
+		if part != "" && parseAlbumTag(part, &info) {
+			parts = append(parts, part)
+		}
+	}
+
+	switch {
+	case len(parts) == 0:
+	case len(parts) == 1:
+		info.Album = parts[0]
+	// Scene names trail the album with catalogue numbers, sources and the group
+	case scene:
+		info.Artist, info.Album = parts[0], parts[1]
+	default:
+		info.Artist, info.Album = parts[0], strings.Join(parts[1:], separator)
+	}
+	return info
+}
+
+// Fills year or disc of info from text and returns false if text is such a tag or a format tag,
+// true if text is part of the name
+func parseAlbumTag(text string, info *metadata.MusicInfo) bool {
+	if yearRegex.MatchString(text) {
+		if info.Year == nil {
+			info.Year = atoi(text)
+		}
+		return false
+	}
+	if disc := parseDisc(text); disc != nil {
+		info.Disc = disc
+		return false
+	}
+	return !isMusicTags(strings.Split(sanitizeName(text), "."))
+}
+
+// Returns disc number if the whole of text names a disc, e.g. "CD2" or "Disc 1", nil otherwise
+func parseDisc(text string) *int {
+	sanitized := sanitizeName(text)
+	for _, re := range patterns.GetDiscPatterns() {
+		match := (*regexp.Regexp)(re).FindStringSubmatch(sanitized)
+		if match != nil && match[0] == sanitized {
+			return atoi(match[1])
+		}
+	}
+	return nil
+}
+
+// Returns true if every segment is a format or source tag, e.g. "FLAC.24BIT.96KHZ" or "WEB"
+func isMusicTags(segments []string) bool {
+	if len(segments) == 0 || segments[0] == "" {
+		return false
+	}
+	for i := 0; i < len(segments); {
+		matched := 0
+		for _, re := range patterns.GetMusicTagPatterns() {
+			if matchSegments(segments[i:], (*regexp.Regexp)(re)) != nil {
+				matched = min(strings.Count((*regexp.Regexp)(re).String(), `\.`)+1, len(segments)-i)
+				break
+			}
+		}
+		if matched == 0 {
+			return false
+		}
+		i += matched
+	}
+	return true
+}
+
+// Returns name with scene underscores turned into spaces, true if name is a scene name without spaces
+// Scene names join words with underscores, or fields with at least two hyphens such as "Artist-Album-WEB-2013-GRP"
+func normalizeMusicName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, " ") || (!strings.Contains(name, "_") && strings.Count(name, "-") < 2) {
+		return name, false
+	}
+	return strings.ReplaceAll(name, "_", " "), true
+}
+
+func atoi(digits string) *int {
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
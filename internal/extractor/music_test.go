@@ -0,0 +1,93 @@
+package extractor
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+func TestExtractMusic(t *testing.T) {
+	logger := slog.Default()
+	tests := []struct{
+		name		string
+		input		string
+		expected	metadata.MusicInfo
+	}{
+		{
+			name:		"album with year and format",
+			input:		"/parent/Artist - Album (2013) [FLAC]",
+			expected:	metadata.MusicInfo{Artist: "Artist", Album: "Album", Year: intPtr(2013)},
+		},
+		{
+			name:		"album keeps edition",
+			input:		"/parent/Artist - Album (Deluxe Edition) [2013] [24bit 96kHz]",
+			expected:	metadata.MusicInfo{Artist: "Artist", Album: "Album (Deluxe Edition)", Year: intPtr(2013)},
+		},
+		{
+			name:		"scene album",
+			input:		"/parent/Artist_Name-Album_Name-WEB-2013-GRP",
+			expected:	metadata.MusicInfo{Artist: "Artist Name", Album: "Album Name", Year: intPtr(2013)},
+		},
+		{
+			name:		"scene album without underscores",
+			input:		"/parent/Artist-Album-WEB-2013-GRP",
+			expected:	metadata.MusicInfo{Artist: "Artist", Album: "Album", Year: intPtr(2013)},
+		},
+		{
+			name:		"disc directory",
+			input:		"/parent/Album/CD2",
+			expected:	metadata.MusicInfo{Disc: intPtr(2)},
+		},
+		{
+			name:		"spaced disc directory",
+			input:		"/parent/Album/Disc 1",
+			expected:	metadata.MusicInfo{Disc: intPtr(1)},
+		},
+		{
+			name:		"numbered track",
+			input:		"/parent/Album/01 - Title.flac",
+			expected:	metadata.MusicInfo{Title: "Title", Track: intPtr(1)},
+		},
+		{
+			name:		"track with disc",
+			input:		"/parent/Album/1-05 Title.mp3",
+			expected:	metadata.MusicInfo{Title: "Title", Track: intPtr(5), Disc: intPtr(1)},
+		},
+		{
+			name:		"artist before track",
+			input:		"/parent/Album/Artist - 01 - Title.flac",
+			expected:	metadata.MusicInfo{Artist: "Artist", Title: "Title", Track: intPtr(1)},
+		},
+		{
+			name:		"artist after track",
+			input:		"/parent/Album/03. Guest - Title.m4a",
+			expected:	metadata.MusicInfo{Artist: "Guest", Title: "Title", Track: intPtr(3)},
+		},
+		{
+			name:		"scene track",
+			input:		"/parent/Album/01-artist_name-track_title.mp3",
+			expected:	metadata.MusicInfo{Artist: "artist name", Title: "track title", Track: intPtr(1)},
+		},
+		{
+			name:		"scene track without underscores",
+			input:		"/parent/Album/01-artist-title.mp3",
+			expected:	metadata.MusicInfo{Artist: "artist", Title: "title", Track: intPtr(1)},
+		},
+		{
+			name:		"unnumbered track",
+			input:		"/parent/Album/Title.flac",
+			expected:	metadata.MusicInfo{Title: "Title"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := ExtractMusic(test.input, logger)
+			if !reflect.DeepEqual(info, test.expected) {
+				t.Errorf("ExtractMusic = %+v, want %+v", info, test.expected)
+			}
+		})
+	}
+}
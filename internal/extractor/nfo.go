@@ -0,0 +1,29 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+)
+
+// Root elements of nfo files Jellyfin reads, e.g. <movie> or <episodedetails>
+var jellyfinNfoRegex = regexp.MustCompile(`<(movie|tvshow|season|episodedetails|musicvideo|album|artist)[\s>/]`)
+
+// Returns true if nfo file at path holds scene release notes rather than Jellyfin metadata
+// Release notes are plain text, often ASCII art, Jellyfin nfo files are XML
+func IsReleaseNotes(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+
+	head := make([]byte, 4096)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return true
+	}
+	head = bytes.TrimSpace(bytes.TrimPrefix(head[:n], []byte("\xEF\xBB\xBF")))
+	return !bytes.HasPrefix(head, []byte("<")) || !jellyfinNfoRegex.Match(head)
+}
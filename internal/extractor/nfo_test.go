@@ -0,0 +1,48 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsReleaseNotes(t *testing.T) {
+	tests := []struct{
+		name		string
+		content		string
+		expected	bool
+	}{
+		{
+			name:		"movie nfo",
+			content:	"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<movie>\n  <title>Movie</title>\n</movie>\n",
+			expected:	false,
+		},
+		{
+			name:		"episode nfo with bom",
+			content:	"\xEF\xBB\xBF<episodedetails><title>Pilot</title></episodedetails>",
+			expected:	false,
+		},
+		{
+			name:		"ascii art",
+			content:	"   _____ ____  ____\n  / ___// __ \\/ __ \\\n  GRP PRESENTS <movie>\n",
+			expected:	true,
+		},
+		{
+			name:		"empty",
+			content:	"",
+			expected:	true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "release.nfo")
+			if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+				t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+			}
+			if notes := IsReleaseNotes(path); notes != test.expected {
+				t.Errorf("IsReleaseNotes = %v, want %v", notes, test.expected)
+			}
+		})
+	}
+}
@@ -28,22 +28,30 @@ func ExtractPath(path string, logger *slog.Logger) metadata.PathInfo {
 	return pathInfo
 }
 
-// Extracts both content type and extension
+// Extracts both content type and extension from the last segment, tags such as SUB are extensions only there
 // Returns metadata.Unknown and "" if not found or unsupported
 func extractType(segments []string) (metadata.ContentType, string) {
-	for i := range segments {
-		candidates := segments[i:]	
-		if match := parseVideoExt(candidates); match != "" {
-			return metadata.Video, match
-		}
-		if match := parseSubtitleExt(candidates); match != "" {
-			return metadata.Subtitle, match
-		}
-		/* TODO: Audio files not yet supported
-		if match := parseAudioExt(candidates); match != "" {
-			return match
-		}
-		*/
+	if len(segments) == 0 {
+		return metadata.Unknown, ""
+	}
+	candidates := segments[len(segments)-1:]
+	if match := parseVideoExt(candidates); match != "" {
+		return metadata.Video, match
+	}
+	if match := parseSubtitleExt(candidates); match != "" {
+		return metadata.Subtitle, match
+	}
+	if match := parseAudioExt(candidates); match != "" {
+		return metadata.Audio, match
+	}
+	if match := parseImageExt(candidates); match != "" {
+		return metadata.Image, match
+	}
+	if match := parseMetadataExt(candidates); match != "" {
+		return metadata.Metadata, match
+	}
+	if match := parseArchiveExt(candidates); match != "" {
+		return metadata.Archive, match
 	}
 	return metadata.Unknown, ""
 }
@@ -77,3 +85,33 @@ func parseAudioExt(segments []string) string {
 	}
 	return ""
 }
+
+func parseImageExt(segments []string) string {
+	for _, re := range patterns.GetImageExtensionPatterns() {
+		match := matchSegments(segments, (*regexp.Regexp)(re))
+		if match != nil {
+			return match[0]
+		}
+	}
+	return ""
+}
+
+func parseMetadataExt(segments []string) string {
+	for _, re := range patterns.GetMetadataExtensionPatterns() {
+		match := matchSegments(segments, (*regexp.Regexp)(re))
+		if match != nil {
+			return match[0]
+		}
+	}
+	return ""
+}
+
+func parseArchiveExt(segments []string) string {
+	for _, re := range patterns.GetArchiveExtensionPatterns() {
+		match := matchSegments(segments, (*regexp.Regexp)(re))
+		if match != nil {
+			return match[0]
+		}
+	}
+	return ""
+}
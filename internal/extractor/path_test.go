@@ -64,18 +64,65 @@ func TestExtractType(t *testing.T) {
 			expectedType: metadata.Subtitle,
 			expectedExt: "SRT",
 		},
-		/* Add audio later when supported
 		{
-			name:			"valid video extension",
+			name:			"subtitle tag before video extension",
 			input:			[]string{
-				"MY",
-				"VIDEO",
-				"MP4",
+				"MOVIE",
+				"SUB",
+				"MKV",
 			},
 			expectedType: metadata.Video,
-			expectedExt: "MP4",
+			expectedExt: "MKV",
+		},
+		{
+			name:			"extension not last",
+			input:			[]string{
+				"MOVIE",
+				"SUB",
+				"TITLE",
+			},
+			expectedType: metadata.ContentType(metadata.Unknown),
+			expectedExt: "",
+		},
+		{
+			name:			"valid audio extension",
+			input:			[]string{
+				"01",
+				"TRACK",
+				"FLAC",
+			},
+			expectedType: metadata.Audio,
+			expectedExt: "FLAC",
+		},
+		{
+			name:			"valid image extension",
+			input:			[]string{
+				"MOVIE",
+				"POSTER",
+				"JPG",
+			},
+			expectedType: metadata.Image,
+			expectedExt: "JPG",
+		},
+		{
+			name:			"valid metadata extension",
+			input:			[]string{
+				"MOVIE",
+				"NFO",
+			},
+			expectedType: metadata.Metadata,
+			expectedExt: "NFO",
+		},
+		{
+			name:			"valid archive volume extension",
+			input:			[]string{
+				"MOVIE",
+				"2020",
+				"R00",
+			},
+			expectedType: metadata.Archive,
+			expectedExt: "R00",
 		},
-		*/
 		{
 			name:			"invalid extension",
 			input:			[]string{
@@ -0,0 +1,106 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/patterns"
+	"github.com/ENIACore/media_library_manager/internal/subtitle"
+)
+
+// Extracts language and track flags of subtitle file at path
+// Language of text subtitles not naming one is detected from their dialogue
+func ExtractSubtitle(path string, logger *slog.Logger) metadata.SubtitleInfo {
+	log := logger.With("func", "ExtractSubtitle")
+	log.Info("extracting subtitle info from path", "path", path)
+
+	sanitizedName := strings.Split(sanitizeName(filepath.Base(path)), ".")
+	ext := parseSubtitleExt(sanitizedName[len(sanitizedName)-1:])
+	if ext != "" {
+		sanitizedName = sanitizedName[:len(sanitizedName)-1]
+	}
+	subtitleInfo := extractSubtitleTags(sanitizedName)
+
+	if subtitleInfo.Language == "" && subtitle.IsText(ext) && !IsVobSub(path) {
+		subtitleInfo.Language, subtitleInfo.Confidence = detectSubtitleLanguage(path)
+		log.Debug("detected subtitle language from dialogue", "language", subtitleInfo.Language, "confidence", subtitleInfo.Confidence)
+	}
+
+	log.Debug("successfully extracted subtitle info", "subtitle-info", fmt.Sprintf("%+v", subtitleInfo))
+	return subtitleInfo
+}
+
+// Returns language and flags of tags trailing subtitle name without ext, e.g. "MOVIE.EN.SDH.FORCED" or "2.ENGLISH"
+// Extracts from the last segment backwards, stopping at the first segment that is neither language nor flag
+func extractSubtitleTags(segments []string) metadata.SubtitleInfo {
+	var info metadata.SubtitleInfo
+	for i := len(segments) - 1; i >= 0; i-- {
+		candidates := segments[i:i+1]
+		switch parseSubtitleFlag(candidates) {
+		case `FORCED`:
+			info.Forced = true
+			continue
+		case `SDH`:
+			info.HearingImpaired = true
+			continue
+		case `DEFAULT`:
+			info.Default = true
+			continue
+		}
+		if language := parseLanguage(candidates); language != "" {
+			// Last language names the track, e.g. BR of "PT.BR"
+			if info.Language == "" {
+				info.Language = patterns.LanguageCodes[language]
+			}
+			continue
+		}
+		break
+	}
+	return info
+}
+
+// Returns language detected from dialogue of subtitle at path, "" if unreadable or below subtitle.MinConfidence
+func detectSubtitleLanguage(path string) (string, float64) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0
+	}
+	language, confidence := subtitle.DetectLanguage(data)
+	if confidence < subtitle.MinConfidence {
+		return "", 0
+	}
+	return language, confidence
+}
+
+// Returns true if file at path holds VobSub MPEG program stream data rather than MicroDVD text, both use .sub
+func IsVobSub(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, []byte{0x00, 0x00, 0x01, 0xBA})
+}
+
+func parseSubtitleFlag(segments []string) string {
+	for _, group := range patterns.GetSubtitleFlagPatternGroups() {
+		for _, re := range group.Patterns {
+			if matchSegments(segments, (*regexp.Regexp)(re)) != nil {
+				return group.Key
+			}
+		}
+	}
+	return ""
+}
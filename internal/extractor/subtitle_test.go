@@ -0,0 +1,133 @@
+package extractor
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+func TestExtractSubtitle(t *testing.T) {
+	logger := slog.Default()
+	tests := []struct{
+		name		string
+		input		string
+		expected	metadata.SubtitleInfo
+	}{
+		{
+			name:		"forced",
+			input:		"/parent/Movie.2020.eng.forced.srt",
+			expected:	metadata.SubtitleInfo{Language: "en", Forced: true},
+		},
+		{
+			name:		"numbered track",
+			input:		"/parent/Subs/2_English.srt",
+			expected:	metadata.SubtitleInfo{Language: "en"},
+		},
+		{
+			name:		"hearing impaired",
+			input:		"/parent/Movie.en.sdh.srt",
+			expected:	metadata.SubtitleInfo{Language: "en", HearingImpaired: true},
+		},
+		{
+			name:		"default",
+			input:		"/parent/Movie.2020.1080p.ger.default.ass",
+			expected:	metadata.SubtitleInfo{Language: "de", Default: true},
+		},
+		{
+			name:		"regional language",
+			input:		"/parent/Movie.2020.pt.br.srt",
+			expected:	metadata.SubtitleInfo{Language: "pt-BR"},
+		},
+		{
+			name:		"untagged",
+			input:		"/parent/Movie.2020.1080p.srt",
+			expected:	metadata.SubtitleInfo{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := ExtractSubtitle(test.input, logger)
+			if info != test.expected {
+				t.Errorf("ExtractSubtitle = %+v, want %+v", info, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractSubtitleDetected(t *testing.T) {
+	logger := slog.Default()
+	dialogue := "1\n00:00:01,000 --> 00:00:03,000\nJe n'ai aucune idée d'où nous allons ce soir.\n\n" +
+		"2\n00:00:04,000 --> 00:00:06,000\nOn devrait peut-être appeler ta mère et lui demander la route.\n" +
+		"Elle sait toujours rentrer quand nous sommes perdus.\n"
+	dir := t.TempDir()
+
+	tests := []struct{
+		name		string
+		file		string
+		language	string
+		detected	bool
+	}{
+		{
+			name:		"untagged",
+			file:		"1.srt",
+			language:	"fr",
+			detected:	true,
+		},
+		{
+			name:		"named language wins",
+			file:		"Movie.en.srt",
+			language:	"en",
+			detected:	false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.file)
+			if err := os.WriteFile(path, []byte(dialogue), 0644); err != nil {
+				t.Fatalf("Unable to create subtitle %v, error %v", path, err)
+			}
+			info := ExtractSubtitle(path, logger)
+			if info.Language != test.language || (info.Confidence > 0) != test.detected {
+				t.Errorf("ExtractSubtitle = %+v, want language %v detected %v", info, test.language, test.detected)
+			}
+		})
+	}
+}
+
+func TestParseSubtitleFlag(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	string
+	}{
+		{
+			name:		"forced",
+			input:		[]string{"FORCED", "SRT"},
+			expected:	"FORCED",
+		},
+		{
+			name:		"closed captions",
+			input:		[]string{"CC"},
+			expected:	"SDH",
+		},
+		{
+			name:		"not a flag",
+			input:		[]string{"ENG"},
+			expected:	"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flag := parseSubtitleFlag(test.input)
+			if flag != test.expected {
+				t.Errorf("parseSubtitleFlag = %v, want %v", flag, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package gaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+)
+
+// Source of the number of episodes a season is expected to have
+// Implemented by the local episode-count file, metadata providers can implement it as well
+type EpisodeCounter interface {
+	// Returns expected episode count and true if known
+	EpisodeCount(series string, season int) (int, bool)
+}
+
+// Episode counts read from a local JSON file of the form {"Series Title": {"1": 10, "2": 12}}
+// Series titles are matched case insensitively
+type CountFile map[string]map[int]int
+
+type Season struct {
+	Series		string	// Formatted series title, e.g. "Show (2010)"
+	Number		int
+	Episodes	[]int	// Sorted unique episode numbers found
+	Missing		[]int
+	Duplicates	[]int
+	Expected	int		// Expected episode count, 0 if unknown
+}
+
+// Reads episode count file, a missing file returns an empty CountFile
+func LoadCountFile(path string) (CountFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return CountFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read episode count file %s, %w", path, err)
+	}
+
+	var raw map[string]map[int]int
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode episode count file %s, %w", path, err)
+	}
+
+	counts := make(CountFile, len(raw))
+	for series, seasons := range raw {
+		counts[strings.ToLower(series)] = seasons
+	}
+	return counts, nil
+}
+
+func (counts CountFile) EpisodeCount(series string, season int) (int, bool) {
+	count, ok := counts[strings.ToLower(series)][season]
+	return count, ok
+}
+
+// Returns missing and duplicate episode numbers of every season found beneath root
+// Missing episodes past the last found one are only reported when counter knows the season length
+func Find(root *metadata.Entry, counter EpisodeCounter, logger *slog.Logger) []Season {
+	log := logger.With("func", "Find")
+	log.Info("finding episode gaps", "path", root.PathInfo.Source)
+
+	type key struct {
+		series	string
+		title	string
+		season	int
+	}
+	found := make(map[key]map[int]int)
+	walk(root, func(entry *metadata.Entry) {
+		if entry.Type != metadata.Video || entry.Bonus != "" {
+			return
+		}
+		info := entry.Inherited()
+		if !naming.IsEpisode(info) {
+			return
+		}
+
+		fields := naming.NewFields(info)
+		k := key{series: seriesName(fields), title: fields.Title, season: fields.Season}
+		if found[k] == nil {
+			found[k] = make(map[int]int)
+		}
+		// Multi-episode files count towards every episode they hold
+		episodes := info.Episodes
+		if episodes == nil {
+			episodes = []int{fields.Episode}
+		}
+		for _, ep := range episodes {
+			found[k][ep]++
+		}
+	})
+
+	seasons := make([]Season, 0, len(found))
+	for k, episodes := range found {
+		season := Season{Series: k.series, Number: k.season}
+		if counter != nil {
+			if count, ok := counter.EpisodeCount(k.title, k.season); ok {
+				season.Expected = count
+			} else if count, ok := counter.EpisodeCount(k.series, k.season); ok {
+				season.Expected = count
+			}
+		}
+
+		last := season.Expected
+		for ep, n := range episodes {
+			season.Episodes = append(season.Episodes, ep)
+			if n > 1 {
+				season.Duplicates = append(season.Duplicates, ep)
+			}
+			last = max(last, ep)
+		}
+		for ep := 1; ep <= last; ep++ {
+			if episodes[ep] == 0 {
+				season.Missing = append(season.Missing, ep)
+			}
+		}
+		sort.Ints(season.Episodes)
+		sort.Ints(season.Duplicates)
+		seasons = append(seasons, season)
+	}
+
+	sort.Slice(seasons, func(i, j int) bool {
+		if seasons[i].Series != seasons[j].Series {
+			return seasons[i].Series < seasons[j].Series
+		}
+		return seasons[i].Number < seasons[j].Number
+	})
+
+	log.Info("finished finding episode gaps", "seasons", len(seasons))
+	return seasons
+}
+
+// Returns summary such as "S02: E01-E10 except E07, duplicate E03"
+func (season Season) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "S%02d: ", season.Number)
+
+	last := 0
+	if len(season.Episodes) > 0 {
+		last = season.Episodes[len(season.Episodes)-1]
+	}
+	last = max(last, season.Expected)
+	fmt.Fprintf(&builder, "E01-E%02d", last)
+
+	if len(season.Missing) > 0 {
+		fmt.Fprintf(&builder, " except %s", formatEpisodes(season.Missing))
+	}
+	if len(season.Duplicates) > 0 {
+		fmt.Fprintf(&builder, ", duplicate %s", formatEpisodes(season.Duplicates))
+	}
+	if season.Expected > 0 {
+		fmt.Fprintf(&builder, " (expected %d)", season.Expected)
+	}
+	return builder.String()
+}
+
+// Returns true if season has missing or duplicate episodes
+func (season Season) HasGaps() bool {
+	return len(season.Missing) > 0 || len(season.Duplicates) > 0
+}
+
+func formatEpisodes(episodes []int) string {
+	res := make([]string, len(episodes))
+	for i, ep := range episodes {
+		res[i] = fmt.Sprintf("E%02d", ep)
+	}
+	return strings.Join(res, ", ")
+}
+
+func seriesName(fields naming.Fields) string {
+	if fields.Year == 0 {
+		return fields.Title
+	}
+	return fmt.Sprintf("%s (%d)", fields.Title, fields.Year)
+}
+
+// Calls fn for entry and every descendant, parents before children
+func walk(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	fn(entry)
+	for _, child := range entry.Children {
+		walk(child, fn)
+	}
+}
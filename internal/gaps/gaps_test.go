@@ -0,0 +1,89 @@
+package gaps
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+func TestFind(t *testing.T) {
+	library := t.TempDir()
+	files := []string{
+		"Shows/Show (2010)/Season 02/Show S02E01.mkv",
+		"Shows/Show (2010)/Season 02/Show S02E02.mkv",
+		"Shows/Show (2010)/Season 02/Show S02E03.mkv",
+		"Shows/Show (2010)/Season 02/Show S02E03.720p.mkv",
+		"Shows/Show (2010)/Season 02/Show S02E05-E06.mkv",
+		"Shows/Other/Season 01/Other S01E01.mkv",
+		"Movies/Movie (2020)/Movie (2020).mkv",
+	}
+	for _, file := range files {
+		path := filepath.Join(library, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+		}
+	}
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(library, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	counts := CountFile{"other": {1: 3}}
+	seasons := Find(root, counts, logger)
+	if len(seasons) != 2 {
+		t.Fatalf("Find len = %v, want 2, got %+v", len(seasons), seasons)
+	}
+
+	other := seasons[0]
+	if other.Series != "Other" || other.Expected != 3 || !reflect.DeepEqual(other.Missing, []int{2, 3}) {
+		t.Errorf("Find Other = %+v, want missing [2 3] of expected 3", other)
+	}
+
+	show := seasons[1]
+	if show.Series != "Show (2010)" || show.Number != 2 {
+		t.Errorf("Find Show = %+v, want Show (2010) season 2", show)
+	}
+	if !reflect.DeepEqual(show.Missing, []int{4}) {
+		t.Errorf("Find Show Missing = %v, want [4]", show.Missing)
+	}
+	if !reflect.DeepEqual(show.Duplicates, []int{3}) {
+		t.Errorf("Find Show Duplicates = %v, want [3]", show.Duplicates)
+	}
+	if show.String() != "S02: E01-E06 except E04, duplicate E03" {
+		t.Errorf("String = %v, want S02: E01-E06 except E04, duplicate E03", show.String())
+	}
+}
+
+func TestLoadCountFile(t *testing.T) {
+	dir := t.TempDir()
+
+	counts, err := LoadCountFile(filepath.Join(dir, "missing.json"))
+	if err != nil || len(counts) != 0 {
+		t.Errorf("LoadCountFile missing file = %v, %v, want empty", counts, err)
+	}
+
+	path := filepath.Join(dir, "counts.json")
+	os.WriteFile(path, []byte(`{"Show Name": {"1": 10, "2": 12}}`), 0644)
+	counts, err = LoadCountFile(path)
+	if err != nil {
+		t.Fatalf("LoadCountFile returns error %v", err)
+	}
+	if count, ok := counts.EpisodeCount("show name", 2); !ok || count != 12 {
+		t.Errorf("EpisodeCount = %v, %v, want 12, true", count, ok)
+	}
+	if _, ok := counts.EpisodeCount("show name", 3); ok {
+		t.Errorf("EpisodeCount for unknown season ok = true, want false")
+	}
+
+	os.WriteFile(path, []byte(`not json`), 0644)
+	if _, err := LoadCountFile(path); err == nil {
+		t.Errorf("LoadCountFile invalid file returns nil error, want error")
+	}
+}
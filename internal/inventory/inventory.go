@@ -0,0 +1,205 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/ENIACore/media_library_manager/internal/extractor"
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+)
+
+// Codecs whose items are worth re-encoding to save space
+var reencodableCodecs = map[string]bool{
+	`X264`:		true,
+	`XVID`:		true,
+	`DIVX`:		true,
+	`MPEG4`:	true,
+	`MPEG2`:	true,
+	`VC1`:		true,
+}
+
+// Single library video with the media info parsed from its name
+type Item struct {
+	Path		string	`json:"path"`
+	Title		string	`json:"title"`
+	Year		int		`json:"year,omitempty"`
+	Season		int		`json:"season,omitempty"`
+	Episode		int		`json:"episode,omitempty"`
+	Resolution	string	`json:"resolution"`
+	Codec		string	`json:"codec"`
+	DynamicRange	string	`json:"dynamic_range"`
+	BitDepth	int		`json:"bit_depth,omitempty"`
+	Source		string	`json:"source"`
+	Audio		string	`json:"audio"`
+	Language	string	`json:"language"`
+	Bonus		string	`json:"bonus,omitempty"`
+	Size		int64	`json:"size"`
+}
+
+type Stats struct {
+	Items				int
+	TotalSize			int64
+	SizeByResolution	map[string]int64
+	CountByResolution	map[string]int
+	CountByCodec		map[string]int
+	Reencodable			int		// Items using a codec listed in reencodableCodecs
+	ReencodableSize		int64
+}
+
+// Returns an item for every video beneath root
+// Names in the library rarely carry quality tags, so when origin knows the file the library was
+// filled from, media info is parsed from that original name instead
+func Collect(root *metadata.Entry, origin func(path string) (string, bool), logger *slog.Logger) ([]Item, error) {
+	log := logger.With("func", "Collect")
+	log.Info("collecting inventory", "path", root.PathInfo.Source)
+
+	var items []Item
+	var err error
+	walk(root, func(entry *metadata.Entry) {
+		if err != nil || entry.Type != metadata.Video {
+			return
+		}
+
+		info, statErr := os.Stat(entry.PathInfo.Source)
+		if statErr != nil {
+			err = fmt.Errorf("stat %s, %w", entry.PathInfo.Source, statErr)
+			return
+		}
+
+		media := entry.Inherited()
+		if origin != nil {
+			if source, ok := origin(entry.PathInfo.Source); ok {
+				media = mergeQuality(media, extractor.ExtractMedia(source, logger))
+			}
+		}
+
+		fields := naming.NewFields(media)
+		items = append(items, Item{
+			Path:		entry.PathInfo.Source,
+			Title:		fields.Title,
+			Year:		fields.Year,
+			Season:		fields.Season,
+			Episode:	fields.Episode,
+			Resolution:	fields.Resolution,
+			Codec:		fields.Codec,
+			DynamicRange:	fields.DynamicRange,
+			BitDepth:	fields.BitDepth,
+			Source:		fields.Source,
+			Audio:		fields.Audio,
+			Language:	fields.Language,
+			Bonus:		media.Bonus,
+			Size:		info.Size(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("finished collecting inventory", "items", len(items))
+	return items, nil
+}
+
+// Returns aggregate statistics of items
+func Summarize(items []Item) Stats {
+	stats := Stats{
+		SizeByResolution:	make(map[string]int64),
+		CountByResolution:	make(map[string]int),
+		CountByCodec:		make(map[string]int),
+	}
+	for _, item := range items {
+		stats.Items++
+		stats.TotalSize += item.Size
+		stats.SizeByResolution[orUnknown(item.Resolution)] += item.Size
+		stats.CountByResolution[orUnknown(item.Resolution)]++
+		stats.CountByCodec[orUnknown(item.Codec)]++
+		if reencodableCodecs[item.Codec] {
+			stats.Reencodable++
+			stats.ReencodableSize += item.Size
+		}
+	}
+	return stats
+}
+
+func WriteJSON(w io.Writer, items []Item) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(items); err != nil {
+		return fmt.Errorf("encode inventory json, %w", err)
+	}
+	return nil
+}
+
+func WriteCSV(w io.Writer, items []Item) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"path", "title", "year", "season", "episode", "resolution", "codec", "dynamic_range", "bit_depth", "source", "audio", "language", "bonus", "size"})
+	for _, item := range items {
+		writer.Write([]string{
+			item.Path,
+			item.Title,
+			strconv.Itoa(item.Year),
+			strconv.Itoa(item.Season),
+			strconv.Itoa(item.Episode),
+			item.Resolution,
+			item.Codec,
+			item.DynamicRange,
+			strconv.Itoa(item.BitDepth),
+			item.Source,
+			item.Audio,
+			item.Language,
+			item.Bonus,
+			strconv.FormatInt(item.Size, 10),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write inventory csv, %w", err)
+	}
+	return nil
+}
+
+// Fills quality fields missing from library media info with those of the original name
+func mergeQuality(media metadata.MediaInfo, original metadata.MediaInfo) metadata.MediaInfo {
+	if media.Resolution == "" {
+		media.Resolution = original.Resolution
+	}
+	if media.Codec == "" {
+		media.Codec = original.Codec
+	}
+	if media.DynamicRange == "" {
+		media.DynamicRange = original.DynamicRange
+	}
+	if media.BitDepth == nil {
+		media.BitDepth = original.BitDepth
+	}
+	if media.Source == "" {
+		media.Source = original.Source
+	}
+	if media.Audio == "" {
+		media.Audio = original.Audio
+	}
+	if media.Language == "" {
+		media.Language = original.Language
+	}
+	return media
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "UNKNOWN"
+	}
+	return value
+}
+
+// Calls fn for entry and every descendant, parents before children
+func walk(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	fn(entry)
+	for _, child := range entry.Children {
+		walk(child, fn)
+	}
+}
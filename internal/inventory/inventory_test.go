@@ -0,0 +1,92 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+func TestCollect(t *testing.T) {
+	library := t.TempDir()
+	files := map[string]string{
+		"Movies/Movie (2020)/Movie (2020).mkv":		"12345",
+		"Movies/Film.2019.2160p.x265.mkv":			"1234567",
+		"Shows/Show/Season 01/Show S01E01.mkv":		"123",
+		"Shows/Show/Season 01/Show S01E01.en.srt":	"1",
+	}
+	for file, content := range files {
+		path := filepath.Join(library, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+		}
+	}
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(library, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+
+	movie := filepath.Join(library, "Movies/Movie (2020)/Movie (2020).mkv")
+	origin := func(path string) (string, bool) {
+		if path == movie {
+			return "/downloads/Movie.2020.1080p.BluRay.x264.mkv", true
+		}
+		return "", false
+	}
+
+	items, err := Collect(root, origin, logger)
+	if err != nil {
+		t.Fatalf("Collect returns error %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("Collect len = %v, want 3", len(items))
+	}
+
+	byPath := make(map[string]Item)
+	for _, item := range items {
+		byPath[item.Path] = item
+	}
+	if item := byPath[movie]; item.Resolution != "1080P" || item.Codec != "X264" || item.Size != 5 || item.Year != 2020 {
+		t.Errorf("Collect movie = %+v, want 1080P X264 from origin, size 5, year 2020", item)
+	}
+	if item := byPath[filepath.Join(library, "Movies/Film.2019.2160p.x265.mkv")]; item.Resolution != "4K" || item.Codec != "X265" {
+		t.Errorf("Collect film = %+v, want 4K X265 from library name", item)
+	}
+
+	stats := Summarize(items)
+	if stats.Items != 3 || stats.TotalSize != 15 {
+		t.Errorf("Summarize items = %v size = %v, want 3 and 15", stats.Items, stats.TotalSize)
+	}
+	if stats.Reencodable != 1 || stats.ReencodableSize != 5 {
+		t.Errorf("Summarize reencodable = %v size = %v, want 1 and 5", stats.Reencodable, stats.ReencodableSize)
+	}
+	if stats.SizeByResolution["4K"] != 7 || stats.CountByCodec["UNKNOWN"] != 1 {
+		t.Errorf("Summarize = %+v, want 4K size 7 and one unknown codec", stats)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, items); err != nil {
+		t.Fatalf("WriteJSON returns error %v", err)
+	}
+	var decoded []Item
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 3 {
+		t.Errorf("WriteJSON output decodes to %v items, %v, want 3", len(decoded), err)
+	}
+
+	buf.Reset()
+	if err := WriteCSV(&buf, items); err != nil {
+		t.Fatalf("WriteCSV returns error %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 4 {
+		t.Errorf("WriteCSV output has %v rows, %v, want header and 3 rows", len(rows), err)
+	}
+}
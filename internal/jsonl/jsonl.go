@@ -0,0 +1,113 @@
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File of JSON lines, one value of T per line, appended to and synced on every write
+// Callers keep the values in memory and guard concurrent use
+type File[T any] struct {
+	path	string
+}
+
+// Opens or creates file name inside dir and returns it with the values it holds, oldest first
+func Open[T any](dir string, name string) (*File[T], []T, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("create dir %s, %w", dir, err)
+	}
+
+	f := &File[T]{path: filepath.Join(dir, name)}
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return f, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("open %s, %w", f.path, err)
+	}
+	defer file.Close()
+
+	var values []T
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var value T
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			return nil, nil, fmt.Errorf("decode %s line %d, %w", f.path, line, err)
+		}
+		values = append(values, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read %s, %w", f.path, err)
+	}
+
+	return f, values, nil
+}
+
+// Returns path of file
+func (f *File[T]) Path() string {
+	return f.path
+}
+
+// Appends value as a new line and syncs file to disk
+func (f *File[T]) Append(value T) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode value for %s, %w", f.path, err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open %s, %w", f.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write %s, %w", f.path, err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync %s, %w", f.path, err)
+	}
+	return nil
+}
+
+// Atomically replaces contents of file with values
+func (f *File[T]) Rewrite(values []T) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file for %s, %w", f.path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			tmp.Close()
+			return fmt.Errorf("encode value for %s, %w", f.path, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file %s, %w", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync temp file %s, %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file %s, %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("replace %s, %w", f.path, err)
+	}
+	return nil
+}
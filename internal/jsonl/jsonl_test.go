@@ -0,0 +1,54 @@
+package jsonl
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+type value struct {
+	Name	string	`json:"name"`
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+
+	file, values, err := Open[value](dir, "values.jsonl")
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if len(values) != 0 {
+		t.Errorf("Open on empty dir = %+v, want no values", values)
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := file.Append(value{Name: name}); err != nil {
+			t.Fatalf("Append returns error %v", err)
+		}
+	}
+	_, values, err = Open[value](dir, "values.jsonl")
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if want := []value{{"a"}, {"b"}, {"c"}}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Open after Append = %+v, want %+v", values, want)
+	}
+
+	if err := file.Rewrite([]value{{"c"}}); err != nil {
+		t.Fatalf("Rewrite returns error %v", err)
+	}
+	_, values, err = Open[value](dir, "values.jsonl")
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if want := []value{{"c"}}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Open after Rewrite = %+v, want %+v", values, want)
+	}
+
+	if err := os.WriteFile(file.Path(), []byte("not json\n"), 0644); err != nil {
+		t.Fatalf("Unable to corrupt file %v", err)
+	}
+	if _, _, err := Open[value](dir, "values.jsonl"); err == nil {
+		t.Errorf("Open on corrupt file returns nil error, want error")
+	}
+}
@@ -82,6 +82,11 @@ var getSessionTimestamp = sync.OnceValue(func() string {
 	return formatTimestamp(time.Now())
 })
 
+// Returns timestamp identifying the current run, shared by log dir and processed records
+func Session() string {
+	return getSessionTimestamp()
+}
+
 func NewLogger(cfg *config.Config) *slog.Logger {
 	basepath := filepath.Join(cfg.ManagerPath, "logs", getSessionTimestamp())
 
@@ -1,5 +1,10 @@
 package metadata
 
+import (
+	"path/filepath"
+	"strings"
+)
+
 type Entry struct {
 	Parent		*Entry
 	Children	[]*Entry
@@ -7,6 +12,10 @@ type Entry struct {
 
 	MediaInfo
 	PathInfo
+	Subtitle	SubtitleInfo	// Zero unless Type is Subtitle
+	PairedVideo	*Entry			// Video a subtitle belongs to, nil if unpaired
+	Music		MusicInfo		// Zero unless Type is Audio or entry is a directory named like an album
+	Album		*Entry			// Album directory an audio track belongs to, nil if outside any album
 }
 
 func (entry *Entry) Height() int {
@@ -20,3 +29,77 @@ func (entry *Entry) Height() int {
 	}
 	return maxHeight + 1
 }
+
+// Returns media info of entry with title, year and season filled in from the closest ancestors
+// Allows files such as "Season 01/02.mkv" to take series information from their directories,
+// an explicit season 0 of specials such as "S00E01" is kept
+func (entry *Entry) Inherited() MediaInfo {
+	info := entry.MediaInfo
+	for parent := entry.Parent; parent != nil; parent = parent.Parent {
+		if len(info.Title) == 0 && len(parent.Title) > 0 {
+			info.Title = parent.Title
+		}
+		if info.Year == nil && parent.Year != nil {
+			info.Year = parent.Year
+		}
+		if info.Season == nil && parent.Season != nil && *parent.Season > 0 {
+			info.Season = parent.Season
+		}
+	}
+	return info
+}
+
+// Returns music info of an audio track with artist, album, year and disc filled in from its album
+// Tracks keep their own artist only when the album names none
+// Tracks inside a disc directory of the album, e.g. "Album/CD2/01.flac", take the disc from it
+func (entry *Entry) InheritedMusic() MusicInfo {
+	info := entry.Music
+	if entry.Album == nil {
+		return info
+	}
+	if info.Disc == nil && entry.Parent != nil && entry.Parent != entry.Album {
+		info.Disc = entry.Parent.Music.Disc
+	}
+
+	// Album artist names the folder, a differing track artist is either a guest of a compilation
+	// or a title split at " - ", so it is kept within the title
+	album := entry.Album.Music
+	if album.Artist != "" {
+		if info.Artist != "" && !strings.EqualFold(info.Artist, album.Artist) {
+			info.Title = info.Artist + " - " + info.Title
+		}
+		info.Artist = album.Artist
+	}
+	info.Album = album.Album
+	info.Year = album.Year
+	if info.Disc == nil {
+		info.Disc = album.Disc
+	}
+	return info
+}
+
+// Returns other half of the VobSub .idx/.sub pair entry belongs to, nil if entry is neither
+// or the other half sharing its name is missing
+func (entry *Entry) VobSubHalf() *Entry {
+	var other string
+	switch entry.Ext {
+	case "IDX":
+		other = "SUB"
+	case "SUB":
+		other = "IDX"
+	default:
+		return nil
+	}
+	if entry.Parent == nil {
+		return nil
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(entry.PathInfo.Source), filepath.Ext(entry.PathInfo.Source))
+	for _, sibling := range entry.Parent.Children {
+		name := filepath.Base(sibling.PathInfo.Source)
+		if sibling.Ext == other && strings.EqualFold(strings.TrimSuffix(name, filepath.Ext(name)), stem) {
+			return sibling
+		}
+	}
+	return nil
+}
@@ -94,3 +94,88 @@ func TestHeight(t *testing.T) {
 		})
 	}
 }
+
+func TestInherited(t *testing.T) {
+	year := 2010
+	season := 2
+	episode := 3
+
+	series := &Entry{MediaInfo: MediaInfo{Title: []string{"SHOW"}, Year: &year}}
+	seasonDir := &Entry{Parent: series, MediaInfo: MediaInfo{Season: &season}}
+	episodeFile := &Entry{Parent: seasonDir, MediaInfo: MediaInfo{Episode: &episode}}
+	titledFile := &Entry{Parent: seasonDir, MediaInfo: MediaInfo{Title: []string{"OTHER"}, Episode: &episode}}
+	special := 0
+	specialFile := &Entry{Parent: seasonDir, MediaInfo: MediaInfo{Season: &special, Episode: &episode}}
+
+	tests := []struct{
+		name			string
+		input			*Entry
+		expectedTitle	string
+		expectedYear	int
+		expectedSeason	int
+	}{
+		{
+			name:			"episode inherits series and season",
+			input:			episodeFile,
+			expectedTitle:	"SHOW",
+			expectedYear:	2010,
+			expectedSeason:	2,
+		},
+		{
+			name:			"episode keeps own title",
+			input:			titledFile,
+			expectedTitle:	"OTHER",
+			expectedYear:	2010,
+			expectedSeason:	2,
+		},
+		{
+			name:			"special keeps season 0",
+			input:			specialFile,
+			expectedTitle:	"SHOW",
+			expectedYear:	2010,
+			expectedSeason:	0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := test.input.Inherited()
+			if len(info.Title) != 1 || info.Title[0] != test.expectedTitle {
+				t.Errorf("Inherited Title = %v, want %v", info.Title, test.expectedTitle)
+			}
+			if info.Year == nil || *info.Year != test.expectedYear {
+				t.Errorf("Inherited Year = %v, want %v", info.Year, test.expectedYear)
+			}
+			if info.Season == nil || *info.Season != test.expectedSeason {
+				t.Errorf("Inherited Season = %v, want %v", info.Season, test.expectedSeason)
+			}
+		})
+	}
+}
+
+func TestVobSubHalf(t *testing.T) {
+	dir := &Entry{PathInfo: PathInfo{Source: "/movie"}}
+	idx := &Entry{Parent: dir, PathInfo: PathInfo{Source: "/movie/Movie.en.idx", Ext: "IDX", Type: Subtitle}}
+	sub := &Entry{Parent: dir, PathInfo: PathInfo{Source: "/movie/Movie.EN.sub", Ext: "SUB", Type: Subtitle}}
+	orphan := &Entry{Parent: dir, PathInfo: PathInfo{Source: "/movie/Movie.fr.idx", Ext: "IDX", Type: Subtitle}}
+	srt := &Entry{Parent: dir, PathInfo: PathInfo{Source: "/movie/Movie.en.srt", Ext: "SRT", Type: Subtitle}}
+	dir.Children = []*Entry{idx, sub, orphan, srt}
+
+	tests := []struct{
+		name		string
+		input		*Entry
+		expected	*Entry
+	}{
+		{name: "idx finds sub", input: idx, expected: sub},
+		{name: "sub finds idx", input: sub, expected: idx},
+		{name: "orphaned idx", input: orphan, expected: nil},
+		{name: "not vobsub", input: srt, expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if half := test.input.VobSubHalf(); half != test.expected {
+				t.Errorf("VobSubHalf = %v, want %v", half, test.expected)
+			}
+		})
+	}
+}
@@ -1,19 +1,57 @@
 package metadata
 
+import "time"
+
 type MediaInfo struct {
     Title		[]string
     Year		*int     // nil if not found
     Episode		*int     // nil = no pattern, 0 = pattern but no number, >0 = ep number
+    Episodes	[]int    // nil unless file holds multiple episodes, then every ep number in order
     Season		*int     // nil = no pattern, 0 = pattern but no number, >0 = season number
+    Seasons		[]int    // nil unless multi-season pack, then every season number in order
+    Complete	bool     // true if name marks a complete series
+    AirDate		*time.Time // nil if not found, otherwise air date of a daily show episode
+    AbsoluteEpisode	*int     // nil if not found, episode number counted across seasons, e.g. "Show - 1071"
+    Version		*int     // nil if not found, fansub release version, e.g. 2 for "05v2"
+    Revision	*int     // nil if not found, 1 for PROPER or REPACK, 2 for REPACK2 or REAL.PROPER
+    CRC			string   // "" if not found, upper cased CRC32 checksum tag, e.g. "ABCD1234"
+    ReleaseGroup	string   // "" if not found, known group key, fansub group or whatever trails the last "-"
+    StreamingService	string   // "" if not found, e.g. AMZN, NF, DSNP
     Resolution	string   // "" if not found
     Codec		string	
+    DynamicRange	string   // "" if not found, SDR, HDR10, HDR10+, DV or HLG
+    BitDepth	*int     // nil if not found, bits per color channel
     Source		string
     Audio		string
     Language	string
+    Languages	[]string // nil if not found, ISO 639 code of every language in order found, e.g. ["it", "en"]
+    LanguageFlag	string   // "" if not found, MULTI or DUAL
+    Edition		string   // "" if not found, e.g. DIRECTORS_CUT
+    Part		*int     // nil if not found, part number of a film split across files, e.g. 2 for "CD2" or "Part 2"
 
 	Bonus		string
 }
 
+// Track tags of subtitle files, zero for other content types
+type SubtitleInfo struct {
+    Language		string  // "" if not found, ISO 639 code, e.g. "en"
+    Confidence		float64 // 0 if Language is named, otherwise confidence from 0 to 1 of Language detected from dialogue
+    Forced			bool   // only foreign dialogue is subtitled
+    HearingImpaired	bool   // SDH, CC or HI
+    Default			bool
+}
+
+// Music tags of audio files and album directories, zero for other entries
+// Names keep their original case and punctuation, unlike media titles
+type MusicInfo struct {
+    Artist	string // "" if not found
+    Album	string // "" if not found, name of album directories
+    Title	string // "" if not found, track title of audio files
+    Year	*int   // nil if not found, release year of album directories
+    Track	*int   // nil if not found, track number of audio files
+    Disc	*int   // nil if not found, e.g. 2 for "CD2" directories or "2-05" tracks
+}
+
 type PathInfo struct {
     Dest	string
     Source	string
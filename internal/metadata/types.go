@@ -15,7 +15,9 @@ Bonus Directory
 └── Subtitle File(s) (optional)
 
 Movie Directory
-├── Movie File
+├── Movie File or Part Files
+├── Part Directory(s) (optional)
+│   └── Part File
 ├── Subtitle File (optional)
 ├── Bonus File (optional)
 └── Bonus Directory (optional)
@@ -30,6 +32,11 @@ Series Directory
 ├── Season Directory(s)
 ├── Bonus Directory (optional)
 └── Subtitle Directory (optional)
+
+Album Directory
+├── Track File(s)
+└── Disc Directory(s) (optional)
+    └── Track File(s)
 */
 
 type EntryRole int8
@@ -57,5 +64,8 @@ type ContentType int8
 const (
     Video			ContentType = iota
     Subtitle		
-	//Audio			- Audio classification not yet included		
+	Audio
+	Image		// Artwork such as posters and fanart
+	Metadata	// Jellyfin nfo or scene release notes
+	Archive		// Volume of a RAR, ZIP or 7z set
 )
@@ -0,0 +1,266 @@
+package naming
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+// Values exposed to naming templates
+type Fields struct {
+	Title		string	// Title cased, e.g. "Example Series", track title as named for music
+	Year		int		// 0 if not found
+	Season		int		// 0 if not found
+	Episode		int		// 0 if not found
+	AbsoluteEpisode	int		// 0 if not found, episode number counted across seasons
+	EpisodeRange	string	// Jellyfin episode tag, e.g. "E01" or "E01-E02" for multi-episode files
+	AirDate		string	// Air date of daily show episodes, e.g. "2024-03-15", "" if not found
+	Resolution	string
+	Codec		string
+	DynamicRange	string	// SDR, HDR10, HDR10+, DV or HLG, "" if not found
+	BitDepth	int		// 0 if not found
+	Source		string
+	Audio		string
+	Language	string
+	Languages	[]string	// ISO 639 codes in order found, e.g. ["it", "en"], join with {{join .Languages "."}}
+	LanguageFlag	string	// MULTI or DUAL, "" if not found
+	Edition		string	// Jellyfin edition name, e.g. "Director's Cut", "" if not found
+	Part		int		// 0 if not found, part of a film split across files, named "-part1" by Jellyfin
+	ReleaseGroup	string
+	StreamingService	string	// e.g. AMZN, NF, DSNP
+	Artist		string	// "" unless music
+	Album		string	// "" unless music
+	Track		int		// 0 if not found
+	Disc		int		// 0 if not found
+}
+
+// Compiled movie, episode and daily show templates rendering paths relative to the library root without ext
+// Music template renders paths relative to the music library root
+type Template struct {
+	movie	*template.Template
+	episode	*template.Template
+	daily	*template.Template
+	music	*template.Template
+}
+
+// Functions available to naming templates
+var funcs = template.FuncMap{
+	"join":	strings.Join,
+}
+
+var romanNumeral = regexp.MustCompile(`^[IVX]+$`)
+
+// Display names of edition keys, Jellyfin groups files of one film differing only by " - <edition>"
+var editionNames = map[string]string{
+	`DIRECTORS_CUT`:		"Director's Cut",
+	`EXTENDED`:				"Extended",
+	`UNRATED`:				"Unrated",
+	`UNCUT`:				"Uncut",
+	`THEATRICAL`:			"Theatrical",
+	`FINAL_CUT`:			"Final Cut",
+	`IMAX`:					"IMAX",
+	`CRITERION`:			"Criterion",
+	`REMASTERED`:			"Remastered",
+	`SPECIAL_EDITION`:		"Special Edition",
+	`ANNIVERSARY_EDITION`:	"Anniversary Edition",
+	`COLLECTORS_EDITION`:	"Collector's Edition",
+	`LIMITED_EDITION`:		"Limited Edition",
+	`OPEN_MATTE`:			"Open Matte",
+}
+
+// Compiles movie, episode, daily show and music templates
+func New(movie string, episode string, daily string, music string) (*Template, error) {
+	movieTmpl, err := template.New("movie").Funcs(funcs).Parse(movie)
+	if err != nil {
+		return nil, fmt.Errorf("parse movie template, %w", err)
+	}
+	episodeTmpl, err := template.New("episode").Funcs(funcs).Parse(episode)
+	if err != nil {
+		return nil, fmt.Errorf("parse episode template, %w", err)
+	}
+	dailyTmpl, err := template.New("daily").Funcs(funcs).Parse(daily)
+	if err != nil {
+		return nil, fmt.Errorf("parse daily template, %w", err)
+	}
+	musicTmpl, err := template.New("music").Funcs(funcs).Parse(music)
+	if err != nil {
+		return nil, fmt.Errorf("parse music template, %w", err)
+	}
+	return &Template{movie: movieTmpl, episode: episodeTmpl, daily: dailyTmpl, music: musicTmpl}, nil
+}
+
+// Returns true if media info describes a series episode rather than a movie
+func IsEpisode(info metadata.MediaInfo) bool {
+	return (info.Episode != nil && *info.Episode > 0) || (info.AbsoluteEpisode != nil && *info.AbsoluteEpisode > 0)
+}
+
+// Returns true if media info describes a daily show episode identified by air date rather than episode number
+func IsDaily(info metadata.MediaInfo) bool {
+	return info.AirDate != nil && !IsEpisode(info)
+}
+
+// Returns path relative to library root for media info, ext is appended lower cased
+func (t *Template) Path(info metadata.MediaInfo, ext string) (string, error) {
+	fields := NewFields(info)
+	if fields.Title == "" {
+		return "", errors.New("missing title")
+	}
+
+	tmpl := t.movie
+	if IsEpisode(info) {
+		tmpl = t.episode
+	} else if IsDaily(info) {
+		tmpl = t.daily
+	}
+
+	return render(tmpl, fields, ext)
+}
+
+// Returns path relative to music library root for music info of a track, ext is appended lower cased
+func (t *Template) MusicPath(info metadata.MusicInfo, ext string) (string, error) {
+	fields := NewMusicFields(info)
+	switch {
+	case fields.Artist == "":
+		return "", errors.New("missing artist")
+	case fields.Album == "":
+		return "", errors.New("missing album")
+	case fields.Title == "":
+		return "", errors.New("missing track title")
+	}
+	return render(t.music, fields, ext)
+}
+
+func render(tmpl *template.Template, fields Fields, ext string) (string, error) {
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, fields); err != nil {
+		return "", fmt.Errorf("render %s template, %w", tmpl.Name(), err)
+	}
+
+	path := filepath.Clean(builder.String())
+	if path == "." || filepath.IsAbs(path) || strings.HasPrefix(path, "..") {
+		return "", fmt.Errorf("%s template renders path %q outside of library", tmpl.Name(), path)
+	}
+	if ext != "" {
+		path += "." + strings.ToLower(ext)
+	}
+	return path, nil
+}
+
+// Returns template fields for media info
+func NewFields(info metadata.MediaInfo) Fields {
+	fields := Fields{
+		Title:		formatTitle(info.Title),
+		Resolution:	info.Resolution,
+		Codec:		info.Codec,
+		DynamicRange:	info.DynamicRange,
+		Source:		info.Source,
+		Audio:		info.Audio,
+		Language:	info.Language,
+		Languages:	info.Languages,
+		LanguageFlag:	info.LanguageFlag,
+		Edition:	editionNames[info.Edition],
+		ReleaseGroup:	info.ReleaseGroup,
+		StreamingService:	info.StreamingService,
+	}
+	if info.Year != nil {
+		fields.Year = *info.Year
+	}
+	if info.BitDepth != nil {
+		fields.BitDepth = *info.BitDepth
+	}
+	if info.Part != nil {
+		fields.Part = *info.Part
+	}
+	if info.Season != nil {
+		fields.Season = *info.Season
+	}
+	if info.Episode != nil {
+		fields.Episode = *info.Episode
+	}
+	// Absolutely numbered anime episodes are filed by their absolute number when no episode pattern is found
+	if info.AbsoluteEpisode != nil {
+		fields.AbsoluteEpisode = *info.AbsoluteEpisode
+		if fields.Episode == 0 {
+			fields.Episode = fields.AbsoluteEpisode
+		}
+	}
+	// Episodes without a season pattern are filed under the first season, S00 stays Jellyfin's specials season
+	if fields.Episode > 0 && info.Season == nil {
+		fields.Season = 1
+	}
+	if fields.Episode > 0 {
+		fields.EpisodeRange = fmt.Sprintf("E%02d", fields.Episode)
+	}
+	// Daily shows are filed under the season of their air year
+	if info.AirDate != nil {
+		fields.AirDate = info.AirDate.Format("2006-01-02")
+		if fields.Season == 0 {
+			fields.Season = info.AirDate.Year()
+		}
+	}
+	if len(info.Episodes) > 1 {
+		fields.EpisodeRange = fmt.Sprintf("E%02d-E%02d", info.Episodes[0], info.Episodes[len(info.Episodes)-1])
+	}
+	return fields
+}
+
+// Returns template fields for music info of a track
+func NewMusicFields(info metadata.MusicInfo) Fields {
+	fields := Fields{
+		Title:	info.Title,
+		Artist:	info.Artist,
+		Album:	info.Album,
+	}
+	if info.Year != nil {
+		fields.Year = *info.Year
+	}
+	if info.Track != nil {
+		fields.Track = *info.Track
+	}
+	if info.Disc != nil {
+		fields.Disc = *info.Disc
+	}
+	return fields
+}
+
+// Returns Jellyfin subtitle name suffix following the video stem, e.g. ".en.default.forced.sdh.srt"
+func SubtitleSuffix(info metadata.SubtitleInfo, ext string) string {
+	var builder strings.Builder
+	if info.Language != "" {
+		builder.WriteString("." + info.Language)
+	}
+	if info.Default {
+		builder.WriteString(".default")
+	}
+	if info.Forced {
+		builder.WriteString(".forced")
+	}
+	if info.HearingImpaired {
+		builder.WriteString(".sdh")
+	}
+	if ext != "" {
+		builder.WriteString("." + strings.ToLower(ext))
+	}
+	return builder.String()
+}
+
+// Joins sanitized title segments into title case, roman numerals are kept upper case
+func formatTitle(segments []string) string {
+	words := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if romanNumeral.MatchString(segment) {
+			words = append(words, segment)
+			continue
+		}
+		words = append(words, segment[:1]+strings.ToLower(segment[1:]))
+	}
+	return strings.Join(words, " ")
+}
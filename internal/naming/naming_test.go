@@ -0,0 +1,271 @@
+package naming
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestPath(t *testing.T) {
+	tpl, err := New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("New returns error %v", err)
+	}
+	airDate := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct{
+		name		string
+		info		metadata.MediaInfo
+		ext			string
+		expected	string
+		expectErr	bool
+	}{
+		{
+			name:		"movie with year",
+			info:		metadata.MediaInfo{Title: []string{"THE", "MATRIX"}, Year: intPtr(1999)},
+			ext:		"MKV",
+			expected:	"Movies/The Matrix (1999)/The Matrix (1999).mkv",
+		},
+		{
+			name:		"movie part",
+			info:		metadata.MediaInfo{Title: []string{"MOVIE"}, Year: intPtr(2001), Part: intPtr(2)},
+			ext:		"AVI",
+			expected:	"Movies/Movie (2001)/Movie (2001)-part2.avi",
+		},
+		{
+			name:		"movie edition",
+			info:		metadata.MediaInfo{Title: []string{"BLADE", "RUNNER"}, Year: intPtr(1982), Edition: "FINAL_CUT"},
+			ext:		"MKV",
+			expected:	"Movies/Blade Runner (1982)/Blade Runner (1982) - Final Cut.mkv",
+		},
+		{
+			name:		"movie without year",
+			info:		metadata.MediaInfo{Title: []string{"ROCKY", "II"}},
+			ext:		"MP4",
+			expected:	"Movies/Rocky II/Rocky II.mp4",
+		},
+		{
+			name:		"episode",
+			info:		metadata.MediaInfo{Title: []string{"SHOW"}, Season: intPtr(2), Episode: intPtr(7)},
+			ext:		"MKV",
+			expected:	"Shows/Show/Season 02/Show S02E07.mkv",
+		},
+		{
+			name:		"multi-episode",
+			info:		metadata.MediaInfo{Title: []string{"SHOW"}, Season: intPtr(1), Episode: intPtr(1), Episodes: []int{1, 2, 3}},
+			ext:		"MKV",
+			expected:	"Shows/Show/Season 01/Show S01E01-E03.mkv",
+		},
+		{
+			name:		"special",
+			info:		metadata.MediaInfo{Title: []string{"SHOW"}, Season: intPtr(0), Episode: intPtr(1)},
+			ext:		"MKV",
+			expected:	"Shows/Show/Season 00/Show S00E01.mkv",
+		},
+		{
+			name:		"episode without season",
+			info:		metadata.MediaInfo{Title: []string{"SHOW"}, Year: intPtr(2010), Episode: intPtr(3)},
+			ext:		"MKV",
+			expected:	"Shows/Show (2010)/Season 01/Show S01E03.mkv",
+		},
+		{
+			name:		"absolute episode",
+			info:		metadata.MediaInfo{Title: []string{"SHOW"}, AbsoluteEpisode: intPtr(1071)},
+			ext:		"MKV",
+			expected:	"Shows/Show/Season 01/Show S01E1071.mkv",
+		},
+		{
+			name:		"daily show",
+			info:		metadata.MediaInfo{Title: []string{"SHOW"}, AirDate: &airDate},
+			ext:		"MKV",
+			expected:	"Shows/Show/Season 2024/Show 2024-03-15.mkv",
+		},
+		{
+			name:		"missing title",
+			info:		metadata.MediaInfo{Year: intPtr(1999)},
+			ext:		"MKV",
+			expectErr:	true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := tpl.Path(test.info, test.ext)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("Path returns nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Path returns error %v", err)
+			}
+			if path != test.expected {
+				t.Errorf("Path = %v, want %v", path, test.expected)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("{{.Title", config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate); err == nil {
+		t.Errorf("New with invalid movie template returns nil error, want error")
+	}
+
+	tpl, err := New("../{{.Title}}", config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("New returns error %v", err)
+	}
+	if _, err := tpl.Path(metadata.MediaInfo{Title: []string{"MOVIE"}}, "MKV"); err == nil {
+		t.Errorf("Path outside of library returns nil error, want error")
+	}
+}
+
+func TestPathLanguages(t *testing.T) {
+	tpl, err := New(`{{.Title}}{{if .Languages}} [{{join .Languages "."}}]{{end}}{{if .LanguageFlag}} {{.LanguageFlag}}{{end}}`, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("New returns error %v", err)
+	}
+
+	info := metadata.MediaInfo{Title: []string{"MOVIE"}, Languages: []string{"it", "en"}, LanguageFlag: "MULTI"}
+	path, err := tpl.Path(info, "MKV")
+	if err != nil {
+		t.Fatalf("Path returns error %v", err)
+	}
+	if expected := "Movie [it.en] MULTI.mkv"; path != expected {
+		t.Errorf("Path = %v, want %v", path, expected)
+	}
+}
+
+func TestMusicPath(t *testing.T) {
+	tpl, err := New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("New returns error %v", err)
+	}
+
+	tests := []struct{
+		name		string
+		info		metadata.MusicInfo
+		ext			string
+		expected	string
+		wantErr		bool
+	}{
+		{
+			name:		"track",
+			info:		metadata.MusicInfo{Artist: "Artist", Album: "Album", Title: "Title", Year: intPtr(2013), Track: intPtr(1)},
+			ext:		"FLAC",
+			expected:	"Artist/Album (2013)/01 - Title.flac",
+		},
+		{
+			name:		"disc track",
+			info:		metadata.MusicInfo{Artist: "Artist", Album: "Album", Title: "Title", Track: intPtr(5), Disc: intPtr(2)},
+			ext:		"mp3",
+			expected:	"Artist/Album/2-05 - Title.mp3",
+		},
+		{
+			name:		"unnumbered track",
+			info:		metadata.MusicInfo{Artist: "Artist", Album: "Album", Title: "Title"},
+			ext:		"m4a",
+			expected:	"Artist/Album/Title.m4a",
+		},
+		{
+			name:		"missing artist",
+			info:		metadata.MusicInfo{Album: "Album", Title: "Title"},
+			ext:		"flac",
+			wantErr:	true,
+		},
+		{
+			name:		"missing album",
+			info:		metadata.MusicInfo{Artist: "Artist", Title: "Title"},
+			ext:		"flac",
+			wantErr:	true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := tpl.MusicPath(test.info, test.ext)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("MusicPath error = %v, wantErr %v", err, test.wantErr)
+			}
+			if path != test.expected {
+				t.Errorf("MusicPath = %v, want %v", path, test.expected)
+			}
+		})
+	}
+}
+
+func TestSubtitleSuffix(t *testing.T) {
+	tests := []struct{
+		name		string
+		info		metadata.SubtitleInfo
+		ext			string
+		expected	string
+	}{
+		{
+			name:		"language",
+			info:		metadata.SubtitleInfo{Language: "en"},
+			ext:		"SRT",
+			expected:	".en.srt",
+		},
+		{
+			name:		"every flag",
+			info:		metadata.SubtitleInfo{Language: "pt-BR", Default: true, Forced: true, HearingImpaired: true},
+			ext:		"ASS",
+			expected:	".pt-BR.default.forced.sdh.ass",
+		},
+		{
+			name:		"flag without language",
+			info:		metadata.SubtitleInfo{Forced: true},
+			ext:		"SRT",
+			expected:	".forced.srt",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suffix := SubtitleSuffix(test.info, test.ext)
+			if suffix != test.expected {
+				t.Errorf("SubtitleSuffix = %v, want %v", suffix, test.expected)
+			}
+		})
+	}
+}
+
+func TestFormatTitle(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		[]string
+		expected	string
+	}{
+		{
+			name:		"title case words",
+			input:		[]string{"THE", "DARK", "KNIGHT"},
+			expected:	"The Dark Knight",
+		},
+		{
+			name:		"roman numeral kept",
+			input:		[]string{"ROCKY", "IV"},
+			expected:	"Rocky IV",
+		},
+		{
+			name:		"empty segments skipped",
+			input:		[]string{""},
+			expected:	"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := formatTitle(test.input); res != test.expected {
+				t.Errorf("formatTitle = %v, want %v", res, test.expected)
+			}
+		})
+	}
+}
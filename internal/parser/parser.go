@@ -23,9 +23,18 @@ func ParseTree(path string, parent *metadata.Entry, depth int, logger *slog.Logg
 		PathInfo: extractor.ExtractPath(path, logger),
     }
 	if !info.IsDir() {
+		if node.Type == metadata.Subtitle {
+			node.Subtitle = extractor.ExtractSubtitle(path, logger)
+		}
+		if node.Type == metadata.Audio {
+			node.Music = extractor.ExtractMusic(path, logger)
+		}
 		return node, nil
 	}
 
+	// Directories are typed by their contents, names such as "Artist - Album [FLAC]" end in extension tags
+	node.PathInfo.Type, node.PathInfo.Ext, node.PathInfo.IsDir = metadata.Unknown, "", true
+
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		return nil, fmt.Errorf("read dir %s, %w", path, err)
@@ -41,6 +50,13 @@ func ParseTree(path string, parent *metadata.Entry, depth int, logger *slog.Logg
 		children = append(children, child)
 	}
 	node.Children = children
+	// Directory names only describe albums and discs when they hold tracks or disc directories
+	for _, child := range children {
+		if child.Type == metadata.Audio || (child.Children != nil && child.Music.Disc != nil) {
+			node.Music = extractor.ExtractMusic(path, logger)
+			break
+		}
+	}
     
     return node, nil
 }
@@ -0,0 +1,22 @@
+package patterns
+
+import (
+	"sync"
+)
+
+// Tags of anime fansub names such as "[Group] Show Name - 1071v2 (1080p) [ABCD1234].mkv"
+// Unlike other patterns these match the raw file name, brackets and " - " are lost once sanitized
+var FansubPatternGroups = []PatternGroup{
+	// Matches leading [Group] (e.g., [SubsPlease])
+	{Key: `GROUP`, Patterns: []Pattern{`^\s*\[([^\[\]]+)\]`}},
+	// Matches CRC32 checksum [ABCD1234] or (ABCD1234)
+	{Key: `CRC`, Patterns: []Pattern{`[\[(]([0-9A-Fa-f]{8})[\])]`}},
+	// Matches absolute episode after " - " with optional version (e.g., " - 1071", " - 05v2")
+	{Key: `EPISODE`, Patterns: []Pattern{`\s-\s(\d{1,4})(?:[vV](\d+))?(?:[\s.\[(]|$)`}},
+}
+
+var (
+	GetFansubPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(FansubPatternGroups)
+	})
+)
@@ -19,6 +19,19 @@ var AudioExtensionPatterns = []Pattern{
 	`APE`, `WV`, `DTS`, `AC3`, `MKA`,
 }
 
+var ImageExtensionPatterns = []Pattern{
+	`JPG`, `JPEG`, `PNG`, `WEBP`, `TBN`, `GIF`, `BMP`,
+}
+
+var MetadataExtensionPatterns = []Pattern{
+	`NFO`,
+}
+
+// Volumes of RAR, ZIP and 7z sets, e.g. ".rar", ".r00", ".z01" or ".7z.001"
+var ArchiveExtensionPatterns = []Pattern{
+	`RAR`, `R\d{2,3}`, `ZIP`, `Z\d{2}`, `7Z`, `\d{3}`,
+}
+
 var (
 	GetVideoExtensionPatterns = sync.OnceValue(func() []*CompiledPattern {
 		return compilePatterns(VideoExtensionPatterns)
@@ -29,4 +42,13 @@ var (
 	GetAudioExtensionPatterns = sync.OnceValue(func() []*CompiledPattern {
 		return compilePatterns(AudioExtensionPatterns)
 	})
+	GetImageExtensionPatterns = sync.OnceValue(func() []*CompiledPattern {
+		return compilePatterns(ImageExtensionPatterns)
+	})
+	GetMetadataExtensionPatterns = sync.OnceValue(func() []*CompiledPattern {
+		return compilePatterns(MetadataExtensionPatterns)
+	})
+	GetArchiveExtensionPatterns = sync.OnceValue(func() []*CompiledPattern {
+		return compilePatterns(ArchiveExtensionPatterns)
+	})
 )
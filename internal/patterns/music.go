@@ -0,0 +1,28 @@
+package patterns
+
+import (
+	"sync"
+)
+
+// Disc numbers of multi-disc albums and films split across files, e.g. "CD2", "Disc 1" or "Disk.02"
+var DiscPatterns = []Pattern{
+	`(?:CD|DIS[CK])(\d{1,2})`,
+	`(?:CD|DIS[CK])\.(\d{1,2})`,
+}
+
+// Format and source tags of music releases, dropped from artist and album names
+var MusicTagPatterns = []Pattern{
+	`FLAC`, `MP3`, `AAC`, `ALAC`, `OGG`, `OPUS`, `WAV`, `APE`, `WV`, `M4A`,
+	`LOSSLESS`, `HIRES`, `HI\.RES`, `\d{2,3}(?:K|KBPS)?`, `V[0-2]`, `VBR`, `CBR`,
+	`\d{2}BIT`, `\d{2}\.BIT`, `\d{2}\.\d{2,3}`, `\d{2,3}KHZ`, `\d{2,3}\.\dKHZ`,
+	`WEB`, `CD`, `VINYL`, `LP`, `EP`, `SACD`, `DVDA`, `DVD\.A`, `CDDA`, `CDR`,
+}
+
+var (
+	GetDiscPatterns = sync.OnceValue(func() []*CompiledPattern {
+		return compilePatterns(DiscPatterns)
+	})
+	GetMusicTagPatterns = sync.OnceValue(func() []*CompiledPattern {
+		return compilePatterns(MusicTagPatterns)
+	})
+)
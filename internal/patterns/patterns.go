@@ -21,33 +21,12 @@ type CompiledPatternGroup struct {
 
 // Un-useful metadata of file pertaining to typical patterns found in torrent files
 var MiscPatterns = []Pattern{
-	// === UNUSED QUALITY INDICATORS ===
-	// HDR variants
-	`HDR`, `HDR10`, `HDR10PLUS`, `HDR10\+`, `DOLBY\.VISION`, `DOLBYVISION`, `DV`, `HLG`,
-	// Bit depth
-	`10BIT`, `10\.BIT`, `8BIT`, `8\.BIT`, `12BIT`, `12\.BIT`,
-	// Color
-	`SDR`,
-
 	// === EDITION / VERSION ===
-	`REMASTERED`, `REMASTER`,
-	`EXTENDED`, `EXTENDED\.CUT`, `EXTENDED\.EDITION`,
-	`UNRATED`,
-	`UNCUT`,
-	`DIRECTORS\.CUT`, `DC`,
-	`THEATRICAL`, `THEATRICAL\.CUT`,
-	`CRITERION`, `CC`,
-	`SPECIAL\.EDITION`, `SE`,
-	`ANNIVERSARY`, `ANNIVERSARY\.EDITION`,
-	`COLLECTORS`, `COLLECTORS\.EDITION`, `CE`,
-	`LIMITED`, `LIMITED\.EDITION`,
-	`PROPER`,
-	`REPACK`, `RERIP`,
-	`REAL`,
+	// Editions are extracted through EditionPatternGroups, short forms are too ambiguous to be an edition
+	`CC`, `SE`, `CE`,
+	// Bare forms are release tags, only LIMITED.EDITION, ANNIVERSARY.EDITION and COLLECTORS.EDITION are editions
+	`LIMITED`, `ANNIVERSARY`, `COLLECTORS`,
 	`RETAIL`,
-	`FINAL\.CUT`,
-	`IMAX`,
-	`OPEN\.MATTE`, `OPENMATTE`,
 	`3D`, `HSBS`, `HOU`, `HALF\.SBS`, `FULL\.SBS`,
 
 	// === RELEASE INFO ===
@@ -64,53 +43,9 @@ var MiscPatterns = []Pattern{
 	`DUBBED`, `DUB`,
 	`HARDCODED`, `HC`,
 	`MULTISUBS`, `MULTI\.SUBS`, `MULTISUB`,
-	`MULTI`, `MULTILANG`, `MULTI\.LANG`, `MULTi`,
-
-	// === SCENE / P2P RELEASE GROUPS ===
-	`YIFY`, `YTS`, `YTS\.MX`, `YTS\.AM`, `YTS\.LT`, `YTS\.AG`,
-	`RARBG`,
-	`ETRG`, `ETTV`, `ETHD`,
-	`PSA`, `PSARIPS`,
-	`GALAXYRG`, `GALAXY\.RG`, `GALAXYTV`,
-	`SPARKS`,
-	`GECKOS`,
-	`AMIABLE`,
-	`DRONES`,
-	`FGT`,
-	`EVO`,
-	`CMRG`,
-	`TIGOLE`, `QXRTRIGOLE`,
-	`FLUX`,
-	`NTG`,
-	`EPSILON`,
-	`PLAYNOW`,
-	`HDETG`,
-	`DIMENSION`,
-	`LOL`,
-	`KILLERS`,
-	`AVS`,
-	`SVA`,
-	`FLEET`,
-	`NTEB`,
-	`PAHE`, `PAHE\.IN`, `PAHE\.PH`,
-	`MKVKING`,
-	`ION10`,
-	`AMZN`,
-	`NF`,
-	`HULU`,
-	`DSNP`,
-	`ATVP`,
-	`PCOK`,
-	`HMAX`, `HBO`,
-	`MAX`,
-	`PMTP`,
-	`CRAV`,
-	`SHO`,
-	`STAN`,
-	`CRITERION`,
 
 	// === TV SPECIFIC ===
-	`COMPLETE`, `COMPLETE\.SERIES`,
+	`COMPLETE`,
 	`MINISERIES`, `MINI\.SERIES`,
 	`PILOT`,
 	`FINALE`,
@@ -125,6 +60,24 @@ var MiscPatterns = []Pattern{
 	`AI\.UPSCALE`, `UPSCALED`, `AI\.ENHANCED`,
 }
 
+// Editions of a film that can sit side by side in the library
+var EditionPatternGroups = []PatternGroup{
+	{Key: `DIRECTORS_CUT`, Patterns: []Pattern{`DIRECTORS\.CUT`, `DIRECTOR\.S\.CUT`, `DIRECTORS\.EDITION`, `DC`}},
+	{Key: `EXTENDED`, Patterns: []Pattern{`EXTENDED\.CUT`, `EXTENDED\.EDITION`, `EXTENDED`}},
+	{Key: `UNRATED`, Patterns: []Pattern{`UNRATED`}},
+	{Key: `UNCUT`, Patterns: []Pattern{`UNCUT`}},
+	{Key: `THEATRICAL`, Patterns: []Pattern{`THEATRICAL\.CUT`, `THEATRICAL\.EDITION`, `THEATRICAL`}},
+	{Key: `FINAL_CUT`, Patterns: []Pattern{`FINAL\.CUT`}},
+	{Key: `IMAX`, Patterns: []Pattern{`IMAX\.EDITION`, `IMAX`}},
+	{Key: `CRITERION`, Patterns: []Pattern{`CRITERION\.COLLECTION`, `CRITERION`}},
+	{Key: `REMASTERED`, Patterns: []Pattern{`REMASTERED`, `REMASTER`}},
+	{Key: `SPECIAL_EDITION`, Patterns: []Pattern{`SPECIAL\.EDITION`}},
+	{Key: `ANNIVERSARY_EDITION`, Patterns: []Pattern{`ANNIVERSARY\.EDITION`}},
+	{Key: `COLLECTORS_EDITION`, Patterns: []Pattern{`COLLECTORS\.EDITION`}},
+	{Key: `LIMITED_EDITION`, Patterns: []Pattern{`LIMITED\.EDITION`}},
+	{Key: `OPEN_MATTE`, Patterns: []Pattern{`OPEN\.MATTE`, `OPENMATTE`}},
+}
+
 var LanguagePatternGroups = []PatternGroup{
 	{Key: `ENGLISH`, Patterns: []Pattern{`ENGLISH`, `ENG`, `EN`}},
 	{Key: `SPANISH`, Patterns: []Pattern{`SPANISH`, `CASTELLANO`, `SPA`, `ES`, `ESPAÑOL`}},
@@ -147,6 +100,35 @@ var LanguagePatternGroups = []PatternGroup{
 	{Key: `CHINESE`, Patterns: []Pattern{`CHINESE`, `CHI`, `ZH`}},
 }
 
+// ISO 639-1 codes of language keys, regional variants carry their region, e.g. pt-BR
+var LanguageCodes = map[string]string{
+	`ENGLISH`:				"en",
+	`SPANISH`:				"es",
+	`FRENCH`:				"fr",
+	`GERMAN`:				"de",
+	`ITALIAN`:				"it",
+	`PORTUGUESE`:			"pt",
+	`BRAZILIAN_PORTUGUESE`:	"pt-BR",
+	`RUSSIAN`:				"ru",
+	`JAPANESE`:				"ja",
+	`KOREAN`:				"ko",
+	`ARABIC`:				"ar",
+	`HEBREW`:				"he",
+	`THAI`:					"th",
+	`TURKISH`:				"tr",
+	`GREEK`:				"el",
+	`POLISH`:				"pl",
+	`HUNGARIAN`:			"hu",
+	`CZECH`:				"cs",
+	`CHINESE`:				"zh",
+}
+
+// Releases holding several audio languages without naming them
+var LanguageFlagPatternGroups = []PatternGroup{
+	{Key: `MULTI`, Patterns: []Pattern{`MULTI`, `MULTILANG`, `MULTI\.LANG`, `MULTI\.AUDIO`}},
+	{Key: `DUAL`, Patterns: []Pattern{`DUAL\.AUDIO`, `DUAL`}},
+}
+
 var BonusPatternGroups = []PatternGroup{
 	// Behind the scenes / Making of
 	{Key: `BEHIND_THE_SCENES`, Patterns: []Pattern{
@@ -216,16 +198,33 @@ var BonusPatternGroups = []PatternGroup{
 	}},
 }
 
+// Part numbers of films split across files, e.g. "Part.1" or "PT2", CD and disc numbers are DiscPatterns
+// Titles such as "Deathly Hallows Part 1" carry them too, so they only count following the year
+var PartPatterns = []Pattern{
+	`PART(\d{1,2})`,
+	`PART\.(\d{1,2})`,
+	`PT(\d{1,2})`,
+}
+
 var (
 	GetLanguagePatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
 		return compilePatternGroups(LanguagePatternGroups)
 	})
+	GetLanguageFlagPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(LanguageFlagPatternGroups)
+	})
+	GetEditionPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(EditionPatternGroups)
+	})
 	GetBonusPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
 		return compilePatternGroups(BonusPatternGroups)
 	})
 	GetMiscPatterns = sync.OnceValue(func() []*CompiledPattern {
 		return compilePatterns(MiscPatterns)
 	})
+	GetPartPatterns = sync.OnceValue(func() []*CompiledPattern {
+		return compilePatterns(PartPatterns)
+	})
 )
 
 func compilePatternGroups(patternGroups []PatternGroup) []CompiledPatternGroup {
@@ -143,6 +143,30 @@ var AudioPatternGroups = []PatternGroup{
 	{Key: `DUAL`, Patterns: []Pattern{`DUAL\.AUDIO`, `DUAL`}},
 }
 
+// Ordered by precedence, releases carrying both Dolby Vision and HDR10 layers are reported as DV
+var DynamicRangePatternGroups = []PatternGroup{
+	// Matches DV, DOVI, DOLBY VISION, DOLBYVISION
+	{Key: `DV`, Patterns: []Pattern{`DV`, `DOVI`, `DOLBY\.VISION`, `DOLBYVISION`}},
+	// Matches HDR10PLUS, HDR10P, HDR10 PLUS, "+" is lost when sanitized so HDR10+ reads as HDR10
+	{Key: `HDR10+`, Patterns: []Pattern{`HDR10PLUS`, `HDR10P`, `HDR10\.PLUS`}},
+	// Matches HDR10, HDR
+	{Key: `HDR10`, Patterns: []Pattern{`HDR10`, `HDR`}},
+	// Matches HLG
+	{Key: `HLG`, Patterns: []Pattern{`HLG`}},
+	// Matches SDR
+	{Key: `SDR`, Patterns: []Pattern{`SDR`}},
+}
+
+// Keys are bits per color channel
+var BitDepthPatternGroups = []PatternGroup{
+	// Matches 12BIT, 12 BIT
+	{Key: `12`, Patterns: []Pattern{`12BIT`, `12\.BIT`}},
+	// Matches 10BIT, 10 BIT, HI10P, HI10
+	{Key: `10`, Patterns: []Pattern{`10BIT`, `10\.BIT`, `HI10P`, `HI10`}},
+	// Matches 8BIT, 8 BIT
+	{Key: `8`, Patterns: []Pattern{`8BIT`, `8\.BIT`}},
+}
+
 var (
 	GetResolutionPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
 		return compilePatternGroups(ResolutionPatternGroups)
@@ -156,6 +180,12 @@ var (
 	GetAudioPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
 		return compilePatternGroups(AudioPatternGroups)
 	})
+	GetDynamicRangePatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(DynamicRangePatternGroups)
+	})
+	GetBitDepthPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(BitDepthPatternGroups)
+	})
 	GetQualityPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
 		res := make([]CompiledPatternGroup, len(ResolutionPatternGroups) + len(CodecPatternGroups) + len(SourcePatternGroups) + len(AudioPatternGroups))
 
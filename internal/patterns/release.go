@@ -0,0 +1,87 @@
+package patterns
+
+import (
+	"sync"
+)
+
+// Well known scene and P2P release groups, found anywhere in a name
+var ReleaseGroupPatternGroups = []PatternGroup{
+	{Key: `YTS`, Patterns: []Pattern{`YIFY`, `YTS`, `YTS\.MX`, `YTS\.AM`, `YTS\.LT`, `YTS\.AG`}},
+	{Key: `RARBG`, Patterns: []Pattern{`RARBG`}},
+	{Key: `ETRG`, Patterns: []Pattern{`ETRG`}},
+	{Key: `ETTV`, Patterns: []Pattern{`ETTV`}},
+	{Key: `ETHD`, Patterns: []Pattern{`ETHD`}},
+	{Key: `PSA`, Patterns: []Pattern{`PSA`, `PSARIPS`}},
+	{Key: `GALAXYRG`, Patterns: []Pattern{`GALAXYRG`, `GALAXY\.RG`, `GALAXYTV`}},
+	{Key: `SPARKS`, Patterns: []Pattern{`SPARKS`}},
+	{Key: `GECKOS`, Patterns: []Pattern{`GECKOS`}},
+	{Key: `AMIABLE`, Patterns: []Pattern{`AMIABLE`}},
+	{Key: `DRONES`, Patterns: []Pattern{`DRONES`}},
+	{Key: `FGT`, Patterns: []Pattern{`FGT`}},
+	{Key: `EVO`, Patterns: []Pattern{`EVO`}},
+	{Key: `CMRG`, Patterns: []Pattern{`CMRG`}},
+	{Key: `TIGOLE`, Patterns: []Pattern{`TIGOLE`, `QXRTRIGOLE`}},
+	{Key: `FLUX`, Patterns: []Pattern{`FLUX`}},
+	{Key: `NTG`, Patterns: []Pattern{`NTG`}},
+	{Key: `NTB`, Patterns: []Pattern{`NTB`, `NTEB`}},
+	{Key: `EPSILON`, Patterns: []Pattern{`EPSILON`}},
+	{Key: `PLAYNOW`, Patterns: []Pattern{`PLAYNOW`}},
+	{Key: `HDETG`, Patterns: []Pattern{`HDETG`}},
+	{Key: `DIMENSION`, Patterns: []Pattern{`DIMENSION`}},
+	{Key: `LOL`, Patterns: []Pattern{`LOL`}},
+	{Key: `KILLERS`, Patterns: []Pattern{`KILLERS`}},
+	{Key: `AVS`, Patterns: []Pattern{`AVS`}},
+	{Key: `SVA`, Patterns: []Pattern{`SVA`}},
+	{Key: `FLEET`, Patterns: []Pattern{`FLEET`}},
+	{Key: `PAHE`, Patterns: []Pattern{`PAHE`, `PAHE\.IN`, `PAHE\.PH`}},
+	{Key: `MKVKING`, Patterns: []Pattern{`MKVKING`}},
+	{Key: `ION10`, Patterns: []Pattern{`ION10`}},
+}
+
+// Release group trailing the last "-" of a raw name, e.g. "Movie.2020.1080p.WEB-DL.H.264-FLUX.mkv"
+// Matched against the raw file name as the "-" is lost once sanitized
+var ReleaseGroupSuffixPattern Pattern = `-([A-Za-z0-9]+)(?:\.[A-Za-z0-9]{1,4})?$`
+
+var StreamingServicePatternGroups = []PatternGroup{
+	// Service names such as NETFLIX, DISNEY, MAX or STAN are left out, they are words found in titles
+	// Matches AMZN
+	{Key: `AMZN`, Patterns: []Pattern{`AMZN`}},
+	// Matches NF
+	{Key: `NF`, Patterns: []Pattern{`NF`}},
+	// Matches DSNP, DSNY
+	{Key: `DSNP`, Patterns: []Pattern{`DSNP`, `DSNY`}},
+	// Matches ATVP, APTV
+	{Key: `ATVP`, Patterns: []Pattern{`ATVP`, `APTV`}},
+	// Matches HMAX
+	{Key: `HMAX`, Patterns: []Pattern{`HMAX`}},
+	// Matches HULU
+	{Key: `HULU`, Patterns: []Pattern{`HULU`}},
+	// Matches PCOK
+	{Key: `PCOK`, Patterns: []Pattern{`PCOK`}},
+	// Matches PMTP
+	{Key: `PMTP`, Patterns: []Pattern{`PMTP`}},
+	// Matches CRAV
+	{Key: `CRAV`, Patterns: []Pattern{`CRAV`}},
+}
+
+// Tags of releases replacing a broken earlier release of the same group
+// REVISION groups capture an optional revision number (e.g., REPACK2), every REAL raises the revision by one
+var RevisionPatternGroups = []PatternGroup{
+	{Key: `REVISION`, Patterns: []Pattern{`PROPER(\d*)`, `REPACK(\d*)`, `RERIP(\d*)`}},
+	{Key: `REAL`, Patterns: []Pattern{`REAL`}},
+}
+
+var (
+	GetRevisionPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(RevisionPatternGroups)
+	})
+	GetReleaseGroupPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(ReleaseGroupPatternGroups)
+	})
+	GetReleaseGroupSuffixPattern = sync.OnceValue(func() *CompiledPattern {
+		return compilePatterns([]Pattern{ReleaseGroupSuffixPattern})[0]
+	})
+	GetStreamingServicePatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(StreamingServicePatternGroups)
+	})
+)
@@ -21,6 +21,8 @@ var SeasonPatterns = []Pattern{
 	`SEASON`,
 	// Matches S<number>E<number>
 	`S(\d+)E\d+`,
+	// Matches S<number>E<number>E<number>... (e.g., S01E01E02)
+	`S(\d+)E\d+(?:E\d+)+`,
 }
 
 var EpisodePatterns = []Pattern{
@@ -42,12 +44,52 @@ var EpisodePatterns = []Pattern{
 	`EP`,
 	// Matches S<number>E<number>
 	`S\d+E(\d+)`,
+	// Matches S<number>E<number>E<number>... capturing first episode (e.g., S01E01E02)
+	`S\d+E(\d+)(?:E\d+)+`,
 	// Matches <number>X<number>
 	`\d+X(\d+)`,
 	// Matches <number>.X.<number> (e.g., 1.X.01, 2.X.15)
 	`\d+\.X\.(\d+)`,
 }
 
+// Episodes of files holding more than one episode
+// LIST groups capture every episode, RANGE groups capture first and last episode
+var MultiEpisodePatternGroups = []PatternGroup{
+	// Matches S<number>E<number>E<number>... (e.g., S01E01E02E03)
+	{Key: `LIST`, Patterns: []Pattern{`S\d+((?:E\d+){2,})`}},
+	// Matches S<number>E<number>-E<number> (e.g., S01E01-E03), "-" is sanitized to "."
+	{Key: `RANGE`, Patterns: []Pattern{`S\d+E(\d+)\.E(\d+)`}},
+}
+
+// Seasons of multi-season packs, RANGE groups capture first and last season
+var MultiSeasonPatternGroups = []PatternGroup{
+	// Matches S<number>-S<number>, SEASONS <number>-<number>, SEASON <number> TO <number> (e.g., S01-S05, Seasons 1-3)
+	{Key: `RANGE`, Patterns: []Pattern{
+		`S(\d+)\.S(\d+)`,
+		`SEASONS?\.(\d+)\.(\d+)`,
+		`SEASONS?\.(\d+)\.TO\.(\d+)`,
+		`S(\d+)\.TO\.S(\d+)`,
+	}},
+}
+
+// Signals that a release holds every season of a series
+var CompleteSeriesPatterns = []Pattern{
+	`COMPLETE\.SERIES`,
+	`THE\.COMPLETE\.SERIES`,
+	`COMPLETE\.SERIE`,
+	`COMPLETE\.SHOW`,
+	`ALL\.SEASONS`,
+}
+
+// Air dates of daily shows such as talk shows and news
+// YMD groups capture year, month and day, DMY groups capture day, month and year
+var AirDatePatternGroups = []PatternGroup{
+	// Matches <year>.<month>.<day> (e.g., 2024.03.15), "-" is sanitized to "."
+	{Key: `YMD`, Patterns: []Pattern{`(\d{4})\.(\d{2})\.(\d{2})`}},
+	// Matches <day>.<month>.<year> (e.g., 15.03.2024)
+	{Key: `DMY`, Patterns: []Pattern{`(\d{2})\.(\d{2})\.(\d{4})`}},
+}
+
 var (
 	GetSeasonPatterns = sync.OnceValue(func() []*CompiledPattern {
 		return compilePatterns(SeasonPatterns)
@@ -55,4 +97,16 @@ var (
 	GetEpisodePatterns = sync.OnceValue(func() []*CompiledPattern {
 		return compilePatterns(EpisodePatterns)
 	})
+	GetMultiEpisodePatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(MultiEpisodePatternGroups)
+	})
+	GetMultiSeasonPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(MultiSeasonPatternGroups)
+	})
+	GetCompleteSeriesPatterns = sync.OnceValue(func() []*CompiledPattern {
+		return compilePatterns(CompleteSeriesPatterns)
+	})
+	GetAirDatePatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(AirDatePatternGroups)
+	})
 )
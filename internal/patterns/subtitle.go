@@ -0,0 +1,20 @@
+package patterns
+
+import (
+	"sync"
+)
+
+// Track flags trailing subtitle names, e.g. "Movie.en.sdh.forced.srt"
+var SubtitleFlagPatternGroups = []PatternGroup{
+	// Only foreign dialogue or signs are subtitled
+	{Key: `FORCED`, Patterns: []Pattern{`FORCED`, `FOREIGN`}},
+	// Subtitles for the deaf and hard of hearing, closed captions
+	{Key: `SDH`, Patterns: []Pattern{`SDH`, `CC`, `HI`}},
+	{Key: `DEFAULT`, Patterns: []Pattern{`DEFAULT`}},
+}
+
+var (
+	GetSubtitleFlagPatternGroups = sync.OnceValue(func() []CompiledPatternGroup {
+		return compilePatternGroups(SubtitleFlagPatternGroups)
+	})
+)
@@ -0,0 +1,110 @@
+package pending
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ENIACore/media_library_manager/internal/jsonl"
+)
+
+const filename = "pending.jsonl"
+
+// Why a file was held back from import
+type Reason string
+
+const (
+	Corrupt	Reason = "corrupt"	// Contents do not match the checksum in its name
+)
+
+// Single file waiting for attention instead of being imported
+type Item struct {
+	Path	string		`json:"path"`
+	Reason	Reason		`json:"reason"`
+	Detail	string		`json:"detail,omitempty"`
+	Session	string		`json:"session"`	// Session timestamp of the run that queued the file
+	AddedAt	time.Time	`json:"added_at"`
+}
+
+// Queue of files held back from import kept as JSON lines inside ManagerPath
+type Queue struct {
+	mu		sync.Mutex
+	file	*jsonl.File[Item]
+	items	[]Item
+}
+
+// Opens or creates pending queue inside managerPath
+func Open(managerPath string) (*Queue, error) {
+	file, items, err := jsonl.Open[Item](managerPath, filename)
+	if err != nil {
+		return nil, fmt.Errorf("open pending queue, %w", err)
+	}
+	return &Queue{file: file, items: items}, nil
+}
+
+// Returns path of pending queue file
+func (queue *Queue) Path() string {
+	return queue.file.Path()
+}
+
+// Appends item to queue unless its path is already queued, AddedAt defaults to now when zero
+func (queue *Queue) Add(item Item) error {
+	if item.AddedAt.IsZero() {
+		item.AddedAt = time.Now()
+	}
+	if queue.Contains(item.Path) {
+		return nil
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if err := queue.file.Append(item); err != nil {
+		return fmt.Errorf("add pending item %s, %w", item.Path, err)
+	}
+	queue.items = append(queue.items, item)
+	return nil
+}
+
+// Returns true if path is waiting in the queue
+func (queue *Queue) Contains(path string) bool {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	for _, item := range queue.items {
+		if item.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns queued items oldest first
+func (queue *Queue) Items() []Item {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	return append([]Item(nil), queue.items...)
+}
+
+// Removes item of path from queue, returns false if path was not queued
+func (queue *Queue) Remove(path string) (bool, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	var kept []Item
+	for _, item := range queue.items {
+		if item.Path != path {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(queue.items) {
+		return false, nil
+	}
+
+	if err := queue.file.Rewrite(kept); err != nil {
+		return false, err
+	}
+	queue.items = kept
+	return true, nil
+}
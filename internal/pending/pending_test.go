@@ -0,0 +1,52 @@
+package pending
+
+import (
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	dir := t.TempDir()
+
+	queue, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if len(queue.Items()) != 0 {
+		t.Errorf("Open on empty dir has items, want none")
+	}
+
+	item := Item{Path: "/downloads/show - 01 [ABCD1234].mkv", Reason: Corrupt, Detail: "crc 00000000"}
+	if err := queue.Add(item); err != nil {
+		t.Fatalf("Add returns error %v", err)
+	}
+	if err := queue.Add(item); err != nil {
+		t.Fatalf("Add duplicate returns error %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if items := reopened.Items(); len(items) != 1 || items[0].Reason != Corrupt {
+		t.Errorf("Items after reopen = %+v, want single corrupt item", items)
+	}
+	if !reopened.Contains(item.Path) {
+		t.Errorf("Contains = false, want true")
+	}
+
+	removed, err := reopened.Remove(item.Path)
+	if err != nil || !removed {
+		t.Fatalf("Remove = %v, %v, want true, nil", removed, err)
+	}
+	if removed, _ := reopened.Remove(item.Path); removed {
+		t.Errorf("Remove of missing item = true, want false")
+	}
+
+	reopened, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open returns error %v", err)
+	}
+	if reopened.Contains(item.Path) {
+		t.Errorf("Contains after Remove = true, want false")
+	}
+}
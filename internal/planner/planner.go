@@ -0,0 +1,404 @@
+package planner
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ENIACore/media_library_manager/internal/extractor"
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+)
+
+type Strategy string
+
+// How a source file is placed at its destination
+const (
+	Move		Strategy = "move"		// Rename, keeps inode so existing hardlinks stay intact
+	Hardlink	Strategy = "hardlink"	// Link into library, source keeps seeding
+	Copy		Strategy = "copy"
+	Convert		Strategy = "convert"	// Write UTF-8 copy of a text subtitle in the format of its dest ext, source keeps seeding untouched
+)
+
+type Action struct {
+	Strategy	Strategy	`json:"strategy"`
+	Source		string		`json:"source"`
+	Dest		string		`json:"dest"`
+	Replace		bool		`json:"replace,omitempty"`	// Dest exists and is superseded by source
+	Video		string		`json:"video,omitempty"`	// Source of the video a sidecar is placed beside, "" for other files
+}
+
+type Skip struct {
+	Path	string
+	Reason	string
+	Dest	string	// Existing destination blocking path, "" for other reasons
+}
+
+type Plan struct {
+	Root		string		// Root of parsed tree the plan was built from
+	Strategy	Strategy
+	Actions		[]Action
+	Skipped		[]Skip
+}
+
+// Matches directory level artwork and nfo files Jellyfin reads without a video stem prefix
+var folderSidecar = regexp.MustCompile(`(?i)^(poster|folder|cover|default|fanart|backdrop|background|art|banner|logo|clearlogo|clearart|disc|discart|landscape|thumb|movie|tvshow|season)(-?\d+)?\.(jpe?g|png|webp|tbn|nfo)$`)
+
+// Matches part of artwork name following video stem Jellyfin reads, e.g. "-poster.jpg" or ".Fanart2.PNG"
+var artworkSuffix = regexp.MustCompile(`(?i)^(?:[-.](poster|fanart|backdrop|thumb|banner|landscape|logo|clearlogo|clearart|disc|discart)(\d*))?\.(jpe?g|png|webp|tbn)$`)
+
+// Plans placing every video beneath root into the library and every album track into the music library
+// using naming template, files sharing a video's name, e.g. subtitles, nfo and artwork, are planned alongside the video
+func Build(root *metadata.Entry, libraryPath string, musicPath string, tpl *naming.Template, strategy Strategy, logger *slog.Logger) Plan {
+	log := logger.With("func", "Build")
+	log.Info("building plan", "root", root.PathInfo.Source, "strategy", strategy)
+
+	plan := Plan{Root: root.PathInfo.Source, Strategy: strategy}
+	dests := make(map[string]string)
+
+	// Single file roots are planned as the only child of a directory
+	if root.Children == nil {
+		root = &metadata.Entry{Children: []*metadata.Entry{root}}
+	}
+	walkDirs(root, func(dir *metadata.Entry) {
+		planDir(&plan, dir, libraryPath, tpl, strategy, dests)
+		planAlbum(&plan, dir, musicPath, tpl, strategy, dests)
+	})
+
+	log.Info("finished building plan", "actions", len(plan.Actions), "skipped", len(plan.Skipped))
+	return plan
+}
+
+// Returns plan without actions, and skips, whose source matches exclude
+func (plan Plan) Without(exclude func(source string) bool) Plan {
+	res := Plan{Root: plan.Root, Strategy: plan.Strategy}
+	for _, action := range plan.Actions {
+		if !exclude(action.Source) {
+			res.Actions = append(res.Actions, action)
+		}
+	}
+	for _, skip := range plan.Skipped {
+		if !exclude(skip.Path) {
+			res.Skipped = append(res.Skipped, skip)
+		}
+	}
+	return res
+}
+
+// Returns plan without actions whose source matches exclude and the sidecars planned beside them,
+// sidecars are reported as skipped
+func (plan Plan) WithoutVideos(exclude func(source string) bool) Plan {
+	res := plan.Without(exclude)
+	var actions []Action
+	for _, action := range res.Actions {
+		if action.Video != "" && exclude(action.Video) {
+			res.Skipped = append(res.Skipped, Skip{Path: action.Source, Reason: fmt.Sprintf("video %s is not placed", action.Video)})
+			continue
+		}
+		actions = append(actions, action)
+	}
+	res.Actions = actions
+	return res
+}
+
+// Returns plan where skips blocked by an existing destination become replacing actions when
+// supersedes reports the source as an upgrade of that destination
+func (plan Plan) Upgrade(supersedes func(source string, dest string) bool) Plan {
+	res := Plan{Root: plan.Root, Strategy: plan.Strategy, Actions: plan.Actions}
+	for _, skip := range plan.Skipped {
+		if skip.Dest != "" && supersedes(skip.Path, skip.Dest) {
+			res.Actions = append(res.Actions, Action{Strategy: plan.Strategy, Source: skip.Path, Dest: skip.Dest, Replace: true})
+			continue
+		}
+		res.Skipped = append(res.Skipped, skip)
+	}
+	return res
+}
+
+// Plans videos directly inside dir together with their sidecar files
+func planDir(plan *Plan, dir *metadata.Entry, libraryPath string, tpl *naming.Template, strategy Strategy, dests map[string]string) {
+	sidecars := matchSidecars(dir)
+	videoDirs := make(map[string]bool)
+	var destStems, videos []string
+	claimed := make(map[*metadata.Entry]bool)
+	for _, files := range sidecars {
+		for _, file := range files {
+			claimed[file] = true
+		}
+	}
+
+	for _, video := range dir.Children {
+		if video.Type != metadata.Video {
+			continue
+		}
+		if video.Bonus != "" {
+			plan.Skipped = append(plan.Skipped, Skip{Path: video.PathInfo.Source, Reason: "bonus content is not placed"})
+			continue
+		}
+
+		rel, err := tpl.Path(video.Inherited(), video.Ext)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, Skip{Path: video.PathInfo.Source, Reason: err.Error()})
+			continue
+		}
+		dest := filepath.Join(libraryPath, rel)
+		videoDirs[filepath.Dir(dest)] = true
+		// Sidecars of a video already in place may still need renaming
+		if !plan.add(strategy, video.PathInfo.Source, dest, dests) && video.PathInfo.Source != dest {
+			continue
+		}
+
+		destStem := strings.TrimSuffix(dest, filepath.Ext(dest))
+		destStems = append(destStems, destStem)
+		videos = append(videos, video.PathInfo.Source)
+		planned := len(plan.Actions)
+		for _, sidecar := range sidecars[video] {
+			suffix, ok := sidecarSuffix(filepath.Base(sidecar.PathInfo.Source), stem(video.PathInfo.Source))
+			if sidecar.Type == metadata.Image {
+				if suffix, ok = jellyfinArtworkSuffix(suffix); !ok {
+					plan.Skipped = append(plan.Skipped, Skip{Path: sidecar.PathInfo.Source, Reason: "artwork name is not read by Jellyfin"})
+					continue
+				}
+			}
+			// Tagged subtitles are renamed so Jellyfin labels their tracks, untagged ones keep their suffix
+			// unless paired without sharing the video's name
+			if sidecar.Type == metadata.Subtitle && (sidecar.Subtitle != (metadata.SubtitleInfo{}) || !ok) {
+				suffix = naming.SubtitleSuffix(sidecar.Subtitle, sidecar.Ext)
+			}
+			if sidecar.Ext == "IDX" || sidecar.Ext == "SUB" {
+				plan.addVobSub(strategy, sidecar, destStem+suffix, dests)
+				continue
+			}
+			plan.add(strategy, sidecar.PathInfo.Source, destStem+suffix, dests)
+		}
+		for i := planned; i < len(plan.Actions); i++ {
+			plan.Actions[i].Video = video.PathInfo.Source
+		}
+	}
+
+	// Scene release notes are dropped, only nfo files Jellyfin reads are carried
+	for _, child := range dir.Children {
+		if len(destStems) > 0 && child.Type == metadata.Metadata && extractor.IsReleaseNotes(child.PathInfo.Source) {
+			plan.Skipped = append(plan.Skipped, Skip{Path: child.PathInfo.Source, Reason: "scene release notes are not placed"})
+		}
+	}
+
+	// An nfo not sharing the name of the only video of dir describes that video
+	if len(destStems) == 1 {
+		for _, child := range dir.Children {
+			name := filepath.Base(child.PathInfo.Source)
+			if claimed[child] || child.Type != metadata.Metadata || folderSidecar.MatchString(name) || !isSidecar(child) {
+				continue
+			}
+			claimed[child] = true
+			if plan.add(strategy, child.PathInfo.Source, destStems[0]+".nfo", dests) {
+				plan.Actions[len(plan.Actions)-1].Video = videos[0]
+			}
+		}
+	}
+
+	// Directory artwork follows videos only when they all land in one directory
+	if len(videoDirs) != 1 {
+		return
+	}
+	for destDir := range videoDirs {
+		for _, child := range dir.Children {
+			name := filepath.Base(child.PathInfo.Source)
+			if claimed[child] || child.Children != nil || !folderSidecar.MatchString(name) || !isSidecar(child) {
+				continue
+			}
+			plan.add(strategy, child.PathInfo.Source, filepath.Join(destDir, name), dests)
+		}
+	}
+}
+
+// Plans tracks of album dir, including those of its disc directories, together with album artwork
+func planAlbum(plan *Plan, dir *metadata.Entry, musicPath string, tpl *naming.Template, strategy Strategy, dests map[string]string) {
+	albumDirs := make(map[string]bool)
+	walkFiles(dir, func(track *metadata.Entry) {
+		if track.Album != dir {
+			return
+		}
+		rel, err := tpl.MusicPath(track.InheritedMusic(), track.Ext)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, Skip{Path: track.PathInfo.Source, Reason: err.Error()})
+			return
+		}
+		dest := filepath.Join(musicPath, rel)
+		albumDirs[filepath.Dir(dest)] = true
+		plan.add(strategy, track.PathInfo.Source, dest, dests)
+	})
+
+	// Album artwork follows tracks only when they all land in one directory
+	if len(albumDirs) != 1 {
+		return
+	}
+	for destDir := range albumDirs {
+		for _, child := range dir.Children {
+			name := filepath.Base(child.PathInfo.Source)
+			if child.Children != nil || !folderSidecar.MatchString(name) || !isSidecar(child) {
+				continue
+			}
+			plan.add(strategy, child.PathInfo.Source, filepath.Join(destDir, name), dests)
+		}
+	}
+}
+
+// Returns files of dir keyed by the video whose name, without ext, is their longest prefix
+// Subtitles paired to a video of dir are keyed by that video, including those inside subtitle folders
+func matchSidecars(dir *metadata.Entry) map[*metadata.Entry][]*metadata.Entry {
+	res := make(map[*metadata.Entry][]*metadata.Entry)
+	for _, file := range dir.Children {
+		if file.Children != nil {
+			walkFiles(file, func(sub *metadata.Entry) {
+				if sub.Type == metadata.Subtitle && sub.PairedVideo != nil && sub.PairedVideo.Parent == dir {
+					res[sub.PairedVideo] = append(res[sub.PairedVideo], sub)
+				}
+			})
+			continue
+		}
+		if !isSidecar(file) {
+			continue
+		}
+		if file.Type == metadata.Subtitle && file.PairedVideo != nil {
+			res[file.PairedVideo] = append(res[file.PairedVideo], file)
+			continue
+		}
+
+		var best *metadata.Entry
+		for _, video := range dir.Children {
+			if video.Type != metadata.Video {
+				continue
+			}
+			videoStem := stem(video.PathInfo.Source)
+			if _, ok := sidecarSuffix(filepath.Base(file.PathInfo.Source), videoStem); !ok {
+				continue
+			}
+			if best == nil || len(videoStem) > len(stem(best.PathInfo.Source)) {
+				best = video
+			}
+		}
+		if best != nil {
+			res[best] = append(res[best], file)
+		}
+	}
+	return res
+}
+
+// Adds action unless it is a no-op or collides, returns false if not added
+func (plan *Plan) add(strategy Strategy, source string, dest string, dests map[string]string) bool {
+	if source == dest {
+		return false
+	}
+	if other, ok := dests[dest]; ok {
+		plan.Skipped = append(plan.Skipped, Skip{Path: source, Reason: fmt.Sprintf("destination %s already planned for %s", dest, other)})
+		return false
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		plan.Skipped = append(plan.Skipped, Skip{Path: source, Reason: fmt.Sprintf("destination %s already exists", dest), Dest: dest})
+		return false
+	}
+
+	dests[dest] = source
+	plan.Actions = append(plan.Actions, Action{Strategy: strategy, Source: source, Dest: dest})
+	return true
+}
+
+// Plans both halves of a VobSub pair under one name or neither, the .sub is placed along with its .idx
+// Orphaned halves are skipped, a lone .sub is only placed when it holds MicroDVD text rather than VobSub data
+func (plan *Plan) addVobSub(strategy Strategy, sidecar *metadata.Entry, dest string, dests map[string]string) {
+	source := sidecar.PathInfo.Source
+	half := sidecar.VobSubHalf()
+	switch {
+	case half == nil && sidecar.Ext == "IDX":
+		plan.Skipped = append(plan.Skipped, Skip{Path: source, Reason: "VobSub index without matching .sub"})
+		return
+	case half == nil && extractor.IsVobSub(source):
+		plan.Skipped = append(plan.Skipped, Skip{Path: source, Reason: "VobSub data without matching .idx"})
+		return
+	case half == nil:
+		plan.add(strategy, source, dest, dests)
+		return
+	case sidecar.Ext == "SUB":
+		return
+	}
+
+	halfSource := half.PathInfo.Source
+	halfDest := strings.TrimSuffix(dest, filepath.Ext(dest)) + "." + strings.ToLower(half.Ext)
+	if !plan.add(strategy, source, dest, dests) && source != dest {
+		plan.Skipped = append(plan.Skipped, Skip{Path: halfSource, Reason: fmt.Sprintf("VobSub index %s not placed", source)})
+		return
+	}
+	if !plan.add(strategy, halfSource, halfDest, dests) && halfSource != halfDest && source != dest {
+		plan.Actions = plan.Actions[:len(plan.Actions)-1]
+		delete(dests, dest)
+		plan.Skipped = append(plan.Skipped, Skip{Path: source, Reason: fmt.Sprintf("VobSub data %s not placed", halfSource)})
+	}
+}
+
+// Returns true if file is a subtitle, artwork or an nfo Jellyfin reads rather than scene release notes
+func isSidecar(file *metadata.Entry) bool {
+	switch file.Type {
+	case metadata.Subtitle, metadata.Image:
+		return true
+	case metadata.Metadata:
+		return !extractor.IsReleaseNotes(file.PathInfo.Source)
+	}
+	return false
+}
+
+// Returns artwork suffix following video stem in the form Jellyfin reads, e.g. ".Poster.JPG" becomes "-poster.jpg"
+// Returns false for other images such as screenshots
+func jellyfinArtworkSuffix(suffix string) (string, bool) {
+	match := artworkSuffix.FindStringSubmatch(suffix)
+	if match == nil {
+		return "", false
+	}
+	if match[1] == "" {
+		return "." + strings.ToLower(match[3]), true
+	}
+	return "-" + strings.ToLower(match[1]) + match[2] + "." + strings.ToLower(match[3]), true
+}
+
+// Returns part of sidecar name following video stem, e.g. ".en.srt" or "-poster.jpg"
+func sidecarSuffix(name string, videoStem string) (string, bool) {
+	if len(name) <= len(videoStem) || !strings.EqualFold(name[:len(videoStem)], videoStem) {
+		return "", false
+	}
+	suffix := name[len(videoStem):]
+	if suffix[0] != '.' && suffix[0] != '-' {
+		return "", false
+	}
+	return suffix, true
+}
+
+// Returns file name without directory and ext
+func stem(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// Calls fn for every file beneath entry
+func walkFiles(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	for _, child := range entry.Children {
+		if child.Children == nil {
+			fn(child)
+			continue
+		}
+		walkFiles(child, fn)
+	}
+}
+
+// Calls fn for entry and every descendant directory
+func walkDirs(entry *metadata.Entry, fn func(*metadata.Entry)) {
+	if entry.Children == nil {
+		return
+	}
+	fn(entry)
+	for _, child := range entry.Children {
+		walkDirs(child, fn)
+	}
+}
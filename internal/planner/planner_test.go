@@ -0,0 +1,394 @@
+package planner
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/classifier"
+	"github.com/ENIACore/media_library_manager/internal/config"
+	"github.com/ENIACore/media_library_manager/internal/naming"
+	"github.com/ENIACore/media_library_manager/internal/parser"
+)
+
+func TestBuild(t *testing.T) {
+	media := createDummyTree(t, []string{
+		"Movie.2020.1080p/Movie.2020.1080p.mkv",
+		"Movie.2020.1080p/Movie.2020.1080p.en.srt",
+		"Movie.2020.1080p/Movie.2020.1080p.eng.forced.srt",
+		"Movie.2020.1080p/Movie.2020.1080p.English.SDH.SRT",
+		"Movie.2020.1080p/Movie.2020.1080p.nfo",
+		"Movie.2020.1080p/poster.jpg",
+		"Movie.2020.1080p/notes.txt",
+		"Show.S01/Show.S01E01.mkv",
+		"Show.S01/Show.S01E01.srt",
+		"Show.S01/Show.S01E02.mkv",
+	})
+	nfo := filepath.Join(media, "Movie.2020.1080p/Movie.2020.1080p.nfo")
+	if err := os.WriteFile(nfo, []byte("<?xml version=\"1.0\"?>\n<movie><title>Movie</title></movie>\n"), 0644); err != nil {
+		t.Fatalf("Unable to create dummy file %v, error %v", nfo, err)
+	}
+	library := t.TempDir()
+	music := t.TempDir()
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(media, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+	tpl, err := naming.New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("naming.New returns error %v", err)
+	}
+
+	plan := Build(root, library, music, tpl, Hardlink, logger)
+
+	expected := map[string]string{
+		"Movie.2020.1080p/Movie.2020.1080p.mkv":	"Movies/Movie (2020)/Movie (2020).mkv",
+		"Movie.2020.1080p/Movie.2020.1080p.en.srt":	"Movies/Movie (2020)/Movie (2020).en.srt",
+		"Movie.2020.1080p/Movie.2020.1080p.eng.forced.srt":	"Movies/Movie (2020)/Movie (2020).en.forced.srt",
+		"Movie.2020.1080p/Movie.2020.1080p.English.SDH.SRT":	"Movies/Movie (2020)/Movie (2020).en.sdh.srt",
+		"Movie.2020.1080p/Movie.2020.1080p.nfo":	"Movies/Movie (2020)/Movie (2020).nfo",
+		"Movie.2020.1080p/poster.jpg":				"Movies/Movie (2020)/poster.jpg",
+		"Show.S01/Show.S01E01.mkv":					"Shows/Show/Season 01/Show S01E01.mkv",
+		"Show.S01/Show.S01E01.srt":					"Shows/Show/Season 01/Show S01E01.srt",
+		"Show.S01/Show.S01E02.mkv":					"Shows/Show/Season 01/Show S01E02.mkv",
+	}
+	if len(plan.Actions) != len(expected) {
+		t.Errorf("Build actions len = %v, want %v, got %+v", len(plan.Actions), len(expected), plan.Actions)
+	}
+	for _, action := range plan.Actions {
+		src, _ := filepath.Rel(media, action.Source)
+		dest, _ := filepath.Rel(library, action.Dest)
+		if expected[src] != dest {
+			t.Errorf("Build dest for %v = %v, want %v", src, dest, expected[src])
+		}
+		if action.Strategy != Hardlink {
+			t.Errorf("Build strategy = %v, want %v", action.Strategy, Hardlink)
+		}
+	}
+
+	// Existing destinations are skipped rather than overwritten
+	existing := filepath.Join(library, "Shows/Show/Season 01/Show S01E02.mkv")
+	os.MkdirAll(filepath.Dir(existing), 0755)
+	os.WriteFile(existing, nil, 0644)
+	plan = Build(root, library, music, tpl, Hardlink, logger)
+	if len(plan.Actions) != len(expected)-1 || len(plan.Skipped) != 1 {
+		t.Errorf("Build with existing dest actions = %v skipped = %v, want %v and 1", len(plan.Actions), len(plan.Skipped), len(expected)-1)
+	}
+
+	upgraded := plan.Upgrade(func(source string, dest string) bool {
+		return dest == existing
+	})
+	if len(upgraded.Actions) != len(expected) || len(upgraded.Skipped) != 0 || !upgraded.Actions[len(expected)-1].Replace {
+		t.Errorf("Upgrade = %+v, want existing dest replaced", upgraded)
+	}
+
+	plan = plan.Without(func(source string) bool {
+		return filepath.Base(source) == "Show.S01E01.mkv"
+	})
+	if len(plan.Actions) != len(expected)-2 {
+		t.Errorf("Without actions len = %v, want %v", len(plan.Actions), len(expected)-2)
+	}
+}
+
+func TestBuildPairedSubtitles(t *testing.T) {
+	media := createDummyTree(t, []string{
+		"Show.S01/Show.S01E01.1080p.WEB.mkv",
+		"Show.S01/Show.S01E02.1080p.WEB.mkv",
+		"Show.S01/Show.S01E02.srt",
+		"Show.S01/Subs/Show.S01E01.1080p.WEB/2_English.srt",
+		"Show.S01/Subs/Show.S01E01.1080p.WEB/3_French.srt",
+	})
+	library := t.TempDir()
+	music := t.TempDir()
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(media, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+	classifier.PairSubtitles(root)
+	tpl, err := naming.New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("naming.New returns error %v", err)
+	}
+
+	plan := Build(root, library, music, tpl, Hardlink, logger)
+
+	expected := map[string]string{
+		"Show.S01/Show.S01E01.1080p.WEB.mkv":					"Shows/Show/Season 01/Show S01E01.mkv",
+		"Show.S01/Show.S01E02.1080p.WEB.mkv":					"Shows/Show/Season 01/Show S01E02.mkv",
+		"Show.S01/Show.S01E02.srt":								"Shows/Show/Season 01/Show S01E02.srt",
+		"Show.S01/Subs/Show.S01E01.1080p.WEB/2_English.srt":	"Shows/Show/Season 01/Show S01E01.en.srt",
+		"Show.S01/Subs/Show.S01E01.1080p.WEB/3_French.srt":		"Shows/Show/Season 01/Show S01E01.fr.srt",
+	}
+	if len(plan.Actions) != len(expected) {
+		t.Errorf("Build actions len = %v, want %v, got %+v", len(plan.Actions), len(expected), plan.Actions)
+	}
+	for _, action := range plan.Actions {
+		src, _ := filepath.Rel(media, action.Source)
+		dest, _ := filepath.Rel(library, action.Dest)
+		if expected[src] != dest {
+			t.Errorf("Build dest for %v = %v, want %v", src, dest, expected[src])
+		}
+	}
+
+	// Paired subtitles leave with their rejected video and are reported
+	skipped := len(plan.Skipped)
+	plan = plan.WithoutVideos(func(source string) bool {
+		return filepath.Base(source) == "Show.S01E01.1080p.WEB.mkv"
+	})
+	if len(plan.Actions) != 2 {
+		t.Errorf("WithoutVideos actions len = %v, want 2, got %+v", len(plan.Actions), plan.Actions)
+	}
+	if len(plan.Skipped) != skipped+2 {
+		t.Errorf("WithoutVideos skipped len = %v, want %v, got %+v", len(plan.Skipped), skipped+2, plan.Skipped)
+	}
+	for _, action := range plan.Actions {
+		if filepath.Base(action.Dest) != "Show S01E02.mkv" && filepath.Base(action.Dest) != "Show S01E02.srt" {
+			t.Errorf("WithoutVideos keeps %v, want only Show S01E02 actions", action.Source)
+		}
+	}
+}
+
+func TestBuildVobSub(t *testing.T) {
+	media := createDummyTree(t, []string{
+		"Movie.2020/Movie.2020.mkv",
+		"Movie.2020/Movie.2020.en.idx",
+		"Movie.2020/Movie.2020.en.sub",
+		"Movie.2020/Movie.2020.fr.idx",
+		"Movie.2020/Movie.2020.it.sub",
+	})
+	// VobSub data starts with an MPEG pack header, MicroDVD text does not
+	orphan := filepath.Join(media, "Movie.2020/Movie.2020.de.sub")
+	if err := os.WriteFile(orphan, []byte{0x00, 0x00, 0x01, 0xBA}, 0644); err != nil {
+		t.Fatalf("Unable to create dummy file %v, error %v", orphan, err)
+	}
+	library := t.TempDir()
+	music := t.TempDir()
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(media, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+	tpl, err := naming.New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("naming.New returns error %v", err)
+	}
+
+	plan := Build(root, library, music, tpl, Hardlink, logger)
+
+	expected := map[string]string{
+		"Movie.2020/Movie.2020.mkv":	"Movies/Movie (2020)/Movie (2020).mkv",
+		"Movie.2020/Movie.2020.en.idx":	"Movies/Movie (2020)/Movie (2020).en.idx",
+		"Movie.2020/Movie.2020.en.sub":	"Movies/Movie (2020)/Movie (2020).en.sub",
+		"Movie.2020/Movie.2020.it.sub":	"Movies/Movie (2020)/Movie (2020).it.sub",
+	}
+	if len(plan.Actions) != len(expected) {
+		t.Errorf("Build actions len = %v, want %v, got %+v", len(plan.Actions), len(expected), plan.Actions)
+	}
+	for _, action := range plan.Actions {
+		src, _ := filepath.Rel(media, action.Source)
+		dest, _ := filepath.Rel(library, action.Dest)
+		if expected[src] != dest {
+			t.Errorf("Build dest for %v = %v, want %v", src, dest, expected[src])
+		}
+	}
+
+	skipped := make(map[string]bool)
+	for _, skip := range plan.Skipped {
+		src, _ := filepath.Rel(media, skip.Path)
+		skipped[src] = true
+	}
+	for _, src := range []string{"Movie.2020/Movie.2020.fr.idx", "Movie.2020/Movie.2020.de.sub"} {
+		if !skipped[src] {
+			t.Errorf("Build did not skip orphaned VobSub half %v, got %+v", src, plan.Skipped)
+		}
+	}
+}
+
+func TestBuildMusic(t *testing.T) {
+	media := createDummyTree(t, []string{
+		"Artist - Album (2013) [FLAC]/CD1/01 - First.flac",
+		"Artist - Album (2013) [FLAC]/CD2/01 - Second.flac",
+		"Artist - Album (2013) [FLAC]/cover.jpg",
+		"Artist - Album (2013) [FLAC]/Album.log",
+		"Singles/Untitled.mp3",
+	})
+	library := t.TempDir()
+	music := t.TempDir()
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(media, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+	classifier.AssignAlbums(root)
+	tpl, err := naming.New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("naming.New returns error %v", err)
+	}
+
+	plan := Build(root, library, music, tpl, Hardlink, logger)
+
+	expected := map[string]string{
+		"Artist - Album (2013) [FLAC]/CD1/01 - First.flac":		"Artist/Album (2013)/1-01 - First.flac",
+		"Artist - Album (2013) [FLAC]/CD2/01 - Second.flac":	"Artist/Album (2013)/2-01 - Second.flac",
+		"Artist - Album (2013) [FLAC]/cover.jpg":				"Artist/Album (2013)/cover.jpg",
+	}
+	if len(plan.Actions) != len(expected) {
+		t.Errorf("Build actions len = %v, want %v, got %+v", len(plan.Actions), len(expected), plan.Actions)
+	}
+	for _, action := range plan.Actions {
+		src, _ := filepath.Rel(media, action.Source)
+		dest, _ := filepath.Rel(music, action.Dest)
+		if expected[src] != dest {
+			t.Errorf("Build dest for %v = %v, want %v", src, dest, expected[src])
+		}
+	}
+	if len(plan.Skipped) != 1 {
+		t.Errorf("Build skipped len = %v, want 1, got %+v", len(plan.Skipped), plan.Skipped)
+	}
+}
+
+func TestBuildArtworkAndNfo(t *testing.T) {
+	media := createDummyTree(t, []string{
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.mkv",
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.Poster.JPG",
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP-fanart.png",
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.screen1.png",
+		"Movie.2020.1080p-GRP/Movie.2020.nfo",
+		"Movie.2020.1080p-GRP/grp.nfo",
+		"Movie.2020.1080p-GRP/fanart.jpg",
+	})
+	if err := os.WriteFile(filepath.Join(media, "Movie.2020.1080p-GRP/Movie.2020.nfo"), []byte("\xEF\xBB\xBF<movie>\n</movie>\n"), 0644); err != nil {
+		t.Fatalf("Unable to create dummy file, error %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(media, "Movie.2020.1080p-GRP/grp.nfo"), []byte("  ___  ___  ___\n GRP PRESENTS\n"), 0644); err != nil {
+		t.Fatalf("Unable to create dummy file, error %v", err)
+	}
+	library := t.TempDir()
+	music := t.TempDir()
+	logger := slog.Default()
+
+	root, err := parser.ParseTree(media, nil, 0, logger)
+	if err != nil {
+		t.Fatalf("ParseTree returns error %v", err)
+	}
+	tpl, err := naming.New(config.DefaultMovieTemplate, config.DefaultEpisodeTemplate, config.DefaultDailyTemplate, config.DefaultMusicTemplate)
+	if err != nil {
+		t.Fatalf("naming.New returns error %v", err)
+	}
+
+	plan := Build(root, library, music, tpl, Hardlink, logger)
+
+	expected := map[string]string{
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.mkv":			"Movies/Movie (2020)/Movie (2020).mkv",
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.Poster.JPG":	"Movies/Movie (2020)/Movie (2020)-poster.jpg",
+		"Movie.2020.1080p-GRP/Movie.2020.1080p-GRP-fanart.png":	"Movies/Movie (2020)/Movie (2020)-fanart.png",
+		"Movie.2020.1080p-GRP/Movie.2020.nfo":						"Movies/Movie (2020)/Movie (2020).nfo",
+		"Movie.2020.1080p-GRP/fanart.jpg":							"Movies/Movie (2020)/fanart.jpg",
+	}
+	if len(plan.Actions) != len(expected) {
+		t.Errorf("Build actions len = %v, want %v, got %+v", len(plan.Actions), len(expected), plan.Actions)
+	}
+	for _, action := range plan.Actions {
+		src, _ := filepath.Rel(media, action.Source)
+		dest, _ := filepath.Rel(library, action.Dest)
+		if expected[src] != dest {
+			t.Errorf("Build dest for %v = %v, want %v", src, dest, expected[src])
+		}
+	}
+
+	skipped := make(map[string]bool)
+	for _, skip := range plan.Skipped {
+		src, _ := filepath.Rel(media, skip.Path)
+		skipped[src] = true
+	}
+	for _, src := range []string{"Movie.2020.1080p-GRP/grp.nfo", "Movie.2020.1080p-GRP/Movie.2020.1080p-GRP.screen1.png"} {
+		if !skipped[src] {
+			t.Errorf("Build did not skip %v, got %+v", src, plan.Skipped)
+		}
+	}
+}
+
+func TestJellyfinArtworkSuffix(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		string
+		expected	string
+		ok			bool
+	}{
+		{name: "plain", input: ".JPG", expected: ".jpg", ok: true},
+		{name: "dash kind", input: "-poster.jpg", expected: "-poster.jpg", ok: true},
+		{name: "dot kind", input: ".Fanart2.PNG", expected: "-fanart2.png", ok: true},
+		{name: "screenshot", input: ".screen1.png", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suffix, ok := jellyfinArtworkSuffix(test.input)
+			if suffix != test.expected || ok != test.ok {
+				t.Errorf("jellyfinArtworkSuffix = %v, %v, want %v, %v", suffix, ok, test.expected, test.ok)
+			}
+		})
+	}
+}
+
+func TestSidecarSuffix(t *testing.T) {
+	tests := []struct{
+		name		string
+		file		string
+		stem		string
+		expected	string
+		ok			bool
+	}{
+		{
+			name:		"subtitle with language",
+			file:		"movie.2020.en.srt",
+			stem:		"Movie.2020",
+			expected:	".en.srt",
+			ok:			true,
+		},
+		{
+			name:		"artwork with dash",
+			file:		"Movie-poster.jpg",
+			stem:		"Movie",
+			expected:	"-poster.jpg",
+			ok:			true,
+		},
+		{
+			name:		"different video sharing prefix",
+			file:		"Movie2.srt",
+			stem:		"Movie",
+			ok:			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suffix, ok := sidecarSuffix(test.file, test.stem)
+			if ok != test.ok || suffix != test.expected {
+				t.Errorf("sidecarSuffix = %v, %v, want %v, %v", suffix, ok, test.expected, test.ok)
+			}
+		})
+	}
+}
+
+// Creates empty files at relative paths inside temporary dir
+func createDummyTree(t *testing.T, files []string) string {
+	dir := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unable to create dummy dir %v, error %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Unable to create dummy file %v, error %v", path, err)
+		}
+	}
+	return dir
+}
@@ -0,0 +1,65 @@
+package quality
+
+import (
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+// Ranks of pattern group keys, higher is better and unknown keys rank 0
+var (
+	resolutionRank = map[string]int{
+		`8K`:		9,
+		`4K`:		8,
+		`2K`:		7,
+		`1080P`:	6,
+		`720P`:		5,
+		`576P`:		4,
+		`480P`:		3,
+		`360P`:		2,
+		`240P`:		1,
+	}
+	sourceRank = map[string]int{
+		`REMUX`:	9,
+		`BLURAY`:	8,
+		`WEB-DL`:	7,
+		`WEBRIP`:	6,
+		`WEB`:		6,
+		`HDRIP`:	5,
+		`HDTV`:		4,
+		`DVDRIP`:	3,
+		`DVD`:		3,
+		`TVRIP`:	2,
+		`SCREENER`:	1,
+	}
+	dynamicRangeRank = map[string]int{
+		`DV`:		4,
+		`HDR10+`:	3,
+		`HDR10`:	2,
+		`HLG`:		1,
+	}
+)
+
+// Returns comparable quality score of media info, higher is better
+// Resolution outweighs source, which outweighs dynamic range, which outweighs bit depth
+func Score(info metadata.MediaInfo) int {
+	bitDepth := 0
+	if info.BitDepth != nil {
+		bitDepth = *info.BitDepth
+	}
+	return resolutionRank[info.Resolution]*10000 +
+		sourceRank[info.Source]*1000 +
+		dynamicRangeRank[info.DynamicRange]*100 +
+		min(bitDepth, 99)
+}
+
+// Returns true if candidate has the same quality as existing and a higher revision, e.g. a REPACK
+// of the release already in the library
+func Supersedes(candidate metadata.MediaInfo, existing metadata.MediaInfo) bool {
+	return Score(candidate) == Score(existing) && revision(candidate) > revision(existing)
+}
+
+func revision(info metadata.MediaInfo) int {
+	if info.Revision == nil {
+		return 0
+	}
+	return *info.Revision
+}
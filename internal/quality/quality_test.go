@@ -0,0 +1,95 @@
+package quality
+
+import (
+	"testing"
+
+	"github.com/ENIACore/media_library_manager/internal/metadata"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct{
+		name	string
+		better	metadata.MediaInfo
+		worse	metadata.MediaInfo
+	}{
+		{
+			name:	"resolution outweighs dynamic range",
+			better:	metadata.MediaInfo{Resolution: "4K"},
+			worse:	metadata.MediaInfo{Resolution: "1080P", DynamicRange: "DV"},
+		},
+		{
+			name:	"dolby vision over hdr10",
+			better:	metadata.MediaInfo{Resolution: "4K", DynamicRange: "DV"},
+			worse:	metadata.MediaInfo{Resolution: "4K", DynamicRange: "HDR10"},
+		},
+		{
+			name:	"hdr10 over sdr",
+			better:	metadata.MediaInfo{Resolution: "4K", DynamicRange: "HDR10"},
+			worse:	metadata.MediaInfo{Resolution: "4K", DynamicRange: "SDR"},
+		},
+		{
+			name:	"10 bit over 8 bit",
+			better:	metadata.MediaInfo{Resolution: "1080P", BitDepth: intPtr(10)},
+			worse:	metadata.MediaInfo{Resolution: "1080P", BitDepth: intPtr(8)},
+		},
+		{
+			name:	"source outweighs bit depth",
+			better:	metadata.MediaInfo{Resolution: "1080P", Source: "BLURAY"},
+			worse:	metadata.MediaInfo{Resolution: "1080P", Source: "WEBRIP", BitDepth: intPtr(10)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if better, worse := Score(test.better), Score(test.worse); better <= worse {
+				t.Errorf("Score = %v, want more than %v", better, worse)
+			}
+		})
+	}
+}
+
+func TestSupersedes(t *testing.T) {
+	tests := []struct{
+		name		string
+		candidate	metadata.MediaInfo
+		existing	metadata.MediaInfo
+		expected	bool
+	}{
+		{
+			name:		"repack of same quality",
+			candidate:	metadata.MediaInfo{Resolution: "1080P", Source: "BLURAY", Revision: intPtr(1)},
+			existing:	metadata.MediaInfo{Resolution: "1080P", Source: "BLURAY"},
+			expected:	true,
+		},
+		{
+			name:		"repack2 over repack",
+			candidate:	metadata.MediaInfo{Resolution: "1080P", Revision: intPtr(2)},
+			existing:	metadata.MediaInfo{Resolution: "1080P", Revision: intPtr(1)},
+			expected:	true,
+		},
+		{
+			name:		"repack of different quality",
+			candidate:	metadata.MediaInfo{Resolution: "720P", Revision: intPtr(1)},
+			existing:	metadata.MediaInfo{Resolution: "1080P"},
+			expected:	false,
+		},
+		{
+			name:		"same revision",
+			candidate:	metadata.MediaInfo{Resolution: "1080P", Revision: intPtr(1)},
+			existing:	metadata.MediaInfo{Resolution: "1080P", Revision: intPtr(1)},
+			expected:	false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := Supersedes(test.candidate, test.existing); res != test.expected {
+				t.Errorf("Supersedes = %v, want %v", res, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,508 @@
+package subtitle
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timed text of a subtitle, lines are separated by "\n" and only <i>, <b> and <u> styling is kept
+type Cue struct {
+	Start	time.Duration
+	End		time.Duration
+	Text	string
+}
+
+var ErrUnsupportedFormat = errors.New("unsupported subtitle format")
+
+// Display time of the last SAMI cue, which has no following sync point to end it
+const lastCueDuration = 5 * time.Second
+
+// Readers of text subtitle formats by upper cased ext, MicroDVD .sub counts frames and is left out
+var readers = map[string]func(text string) ([]Cue, error){
+	"SRT":	readCues,
+	"VTT":	readCues,
+	"SBV":	readSBV,
+	"SSA":	readSSA,
+	"ASS":	readSSA,
+	"SMI":	readSMI,
+	"TTML":	readTTML,
+}
+
+// Writers of formats subtitles are converted to by upper cased ext
+var writers = map[string]func(cues []Cue) []byte{
+	"SRT":	writeSRT,
+	"VTT":	writeVTT,
+}
+
+var (
+	tagRe			= regexp.MustCompile(`<\s*(/?)\s*([a-zA-Z]+)[^>]*>`)
+	overrideRe		= regexp.MustCompile(`\{[^}]*\}`)
+	assStyleRe		= regexp.MustCompile(`\\([ibu])([01])`)
+	syncRe			= regexp.MustCompile(`(?i)<sync[^>]*\bstart\s*=\s*["']?(\d+)[^>]*>`)
+	smiEndRe		= regexp.MustCompile(`(?i)</body|</sami`)
+	breakRe			= regexp.MustCompile(`(?i)<br\s*/?>`)
+	offsetTimeRe	= regexp.MustCompile(`^([\d.]+)(h|m|s|ms|f|t)$`)
+	whitespaceRe	= regexp.MustCompile(`\s+`)
+)
+
+// Returns true if subtitles with upper cased ext can be converted
+func CanRead(ext string) bool {
+	_, ok := readers[ext]
+	return ok
+}
+
+// Returns true if subtitles can be converted to upper cased ext
+func CanWrite(ext string) bool {
+	_, ok := writers[ext]
+	return ok
+}
+
+// Returns subtitle data of format from converted to UTF-8 text of format to, both upper cased exts
+// Timing is kept while styling other than italic, bold and underline is dropped
+func Convert(data []byte, from string, to string) ([]byte, error) {
+	text, _, err := ToUTF8(data)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return text, nil
+	}
+
+	read, ok := readers[from]
+	if !ok {
+		return nil, fmt.Errorf("read %s, %w", from, ErrUnsupportedFormat)
+	}
+	write, ok := writers[to]
+	if !ok {
+		return nil, fmt.Errorf("write %s, %w", to, ErrUnsupportedFormat)
+	}
+
+	cues, err := read(strings.ReplaceAll(string(text), "\r\n", "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s, %w", from, err)
+	}
+	return write(cues), nil
+}
+
+// Reads SRT and WebVTT cues, each a "start --> end" line followed by text up to a blank line
+// Cue numbers, identifiers and WebVTT header, note and style blocks hold no timing line and are skipped
+func readCues(text string) ([]Cue, error) {
+	var cues []Cue
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		for i, line := range lines {
+			start, end, ok := strings.Cut(line, "-->")
+			if !ok {
+				continue
+			}
+			// WebVTT settings follow the end time
+			fields := strings.Fields(end)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("cue %q has no end", line)
+			}
+			cue, err := newCue(start, fields[0], html.UnescapeString(cleanText(strings.Join(lines[i+1:], "\n"))))
+			if err != nil {
+				return nil, err
+			}
+			cues = append(cues, cue)
+			break
+		}
+	}
+	return cues, nil
+}
+
+// Reads SubViewer cues, each a "start,end" line followed by text up to a blank line
+func readSBV(text string) ([]Cue, error) {
+	var cues []Cue
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		start, end, ok := strings.Cut(lines[0], ",")
+		if !ok {
+			continue
+		}
+		cue, err := newCue(start, end, cleanText(strings.Join(lines[1:], "\n")))
+		if err != nil {
+			return nil, err
+		}
+		cues = append(cues, cue)
+	}
+	return cues, nil
+}
+
+// Reads Dialogue lines of SSA/ASS scripts in the field order of the Events format line
+func readSSA(text string) ([]Cue, error) {
+	format := []string{"Layer", "Start", "End", "Style", "Name", "MarginL", "MarginR", "MarginV", "Effect", "Text"}
+	events := false
+
+	var cues []Cue
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			events = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !events || !ok {
+			continue
+		}
+
+		switch key {
+		case "Format":
+			format = strings.Split(value, ",")
+			for i := range format {
+				format[i] = strings.TrimSpace(format[i])
+			}
+		case "Dialogue":
+			fields := strings.SplitN(value, ",", len(format))
+			if len(fields) != len(format) {
+				return nil, fmt.Errorf("dialogue %q has %d fields, want %d", line, len(fields), len(format))
+			}
+			field := make(map[string]string)
+			for i, name := range format {
+				field[name] = strings.TrimSpace(fields[i])
+			}
+			cue, err := newCue(field["Start"], field["End"], ssaText(field["Text"]))
+			if err != nil {
+				return nil, err
+			}
+			cues = append(cues, cue)
+		}
+	}
+
+	// Scripts list dialogue by layer and style rather than by time
+	sort.SliceStable(cues, func(i, j int) bool {
+		return cues[i].Start < cues[j].Start
+	})
+	return cues, nil
+}
+
+// Reads SAMI cues, each sync point shows its text until the next one, an empty one clears the screen
+func readSMI(text string) ([]Cue, error) {
+	syncs := syncRe.FindAllStringSubmatchIndex(text, -1)
+	end := len(text)
+	if loc := smiEndRe.FindStringIndex(text); loc != nil {
+		end = loc[0]
+	}
+
+	var cues []Cue
+	for i, sync := range syncs {
+		if sync[0] >= end {
+			break
+		}
+		ms, err := strconv.Atoi(text[sync[2]:sync[3]])
+		if err != nil {
+			return nil, fmt.Errorf("sync start %q, %w", text[sync[2]:sync[3]], err)
+		}
+		cue := Cue{Start: time.Duration(ms) * time.Millisecond, End: time.Duration(ms)*time.Millisecond + lastCueDuration}
+
+		next := end
+		if i+1 < len(syncs) && syncs[i+1][0] < end {
+			next = syncs[i+1][0]
+			nextMs, err := strconv.Atoi(text[syncs[i+1][2]:syncs[i+1][3]])
+			if err != nil {
+				return nil, fmt.Errorf("sync start %q, %w", text[syncs[i+1][2]:syncs[i+1][3]], err)
+			}
+			cue.End = time.Duration(nextMs) * time.Millisecond
+		}
+
+		content := breakRe.ReplaceAllString(strings.ReplaceAll(text[sync[1]:next], "\n", " "), "\n")
+		// Sync points holding only &nbsp; clear the screen
+		cue.Text = cleanText(strings.ReplaceAll(html.UnescapeString(content), "\u00A0", " "))
+		if cue.Text != "" {
+			cues = append(cues, cue)
+		}
+	}
+	return cues, nil
+}
+
+// Reads timed paragraphs of TTML, spans styled italic, bold or underlined keep their styling
+func readTTML(text string) ([]Cue, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	// Data is already UTF-8 whatever the declaration says
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	rates := ttmlRates{frame: 30, tick: 1}
+	var cues []Cue
+	var cue *Cue
+	var b strings.Builder
+	var closing []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "tt":
+				rates = newTTMLRates(t.Attr)
+			case t.Name.Local == "p":
+				begin, end, err := rates.span(t.Attr)
+				if err != nil {
+					return nil, err
+				}
+				cue = &Cue{Start: begin, End: end}
+				b.Reset()
+				closing = closing[:0]
+			case cue != nil && t.Name.Local == "br":
+				b.WriteString("\n")
+			case cue != nil && t.Name.Local == "span":
+				open, close := ttmlStyle(t.Attr)
+				b.WriteString(open)
+				closing = append(closing, close)
+			}
+		case xml.EndElement:
+			switch {
+			case cue != nil && t.Name.Local == "span" && len(closing) > 0:
+				b.WriteString(closing[len(closing)-1])
+				closing = closing[:len(closing)-1]
+			case cue != nil && t.Name.Local == "p":
+				cue.Text = cleanText(b.String())
+				cues = append(cues, *cue)
+				cue = nil
+			}
+		case xml.CharData:
+			if cue != nil {
+				// Line breaks inside paragraphs are layout of the document, not of the subtitle
+				b.WriteString(whitespaceRe.ReplaceAllString(string(t), " "))
+			}
+		}
+	}
+	return cues, nil
+}
+
+// Frame and tick rates of a TTML document, timing may count either
+type ttmlRates struct {
+	frame	float64
+	tick	float64
+}
+
+func newTTMLRates(attrs []xml.Attr) ttmlRates {
+	rates := ttmlRates{frame: 30, tick: 1}
+	for _, attr := range attrs {
+		value, err := strconv.ParseFloat(attr.Value, 64)
+		if err != nil || value <= 0 {
+			continue
+		}
+		switch attr.Name.Local {
+		case "frameRate":
+			rates.frame = value
+		case "tickRate":
+			rates.tick = value
+		}
+	}
+	return rates
+}
+
+// Returns begin and end of a timed element, end may be given as duration
+func (rates ttmlRates) span(attrs []xml.Attr) (time.Duration, time.Duration, error) {
+	var begin, end, dur string
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "begin":
+			begin = attr.Value
+		case "end":
+			end = attr.Value
+		case "dur":
+			dur = attr.Value
+		}
+	}
+
+	start, err := rates.parse(begin)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end == "" {
+		d, err := rates.parse(dur)
+		return start, start + d, err
+	}
+	stop, err := rates.parse(end)
+	return start, stop, err
+}
+
+// Parses TTML clock time, e.g. "00:00:01.500" or "00:00:01:12" in frames, or offset time, e.g. "1.5s" or "15000000t"
+func (rates ttmlRates) parse(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("missing time")
+	}
+	match := offsetTimeRe.FindStringSubmatch(value)
+	if match == nil {
+		parts := strings.Split(value, ":")
+		if len(parts) != 4 {
+			return parseTimestamp(value)
+		}
+		clock, err := parseTimestamp(strings.Join(parts[:3], ":"))
+		if err != nil {
+			return 0, err
+		}
+		frames, err := strconv.ParseFloat(parts[3], 64)
+		if err != nil {
+			return 0, fmt.Errorf("time %q, %w", value, err)
+		}
+		return clock + time.Duration(frames/rates.frame*float64(time.Second)), nil
+	}
+
+	count, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("time %q, %w", value, err)
+	}
+	unit := map[string]float64{
+		"h":	float64(time.Hour),
+		"m":	float64(time.Minute),
+		"s":	float64(time.Second),
+		"ms":	float64(time.Millisecond),
+		"f":	float64(time.Second) / rates.frame,
+		"t":	float64(time.Second) / rates.tick,
+	}[match[2]]
+	return time.Duration(count * unit), nil
+}
+
+// Returns tags opening and closing styling of a TTML span that cues can keep
+func ttmlStyle(attrs []xml.Attr) (string, string) {
+	var open, close string
+	for _, attr := range attrs {
+		tag := ""
+		switch {
+		case attr.Name.Local == "fontStyle" && attr.Value == "italic":
+			tag = "i"
+		case attr.Name.Local == "fontWeight" && attr.Value == "bold":
+			tag = "b"
+		case attr.Name.Local == "textDecoration" && attr.Value == "underline":
+			tag = "u"
+		}
+		if tag != "" {
+			open += "<" + tag + ">"
+			close = "</" + tag + ">" + close
+		}
+	}
+	return open, close
+}
+
+func newCue(start string, end string, text string) (Cue, error) {
+	var err error
+	cue := Cue{Text: text}
+	if cue.Start, err = parseTimestamp(start); err != nil {
+		return Cue{}, err
+	}
+	if cue.End, err = parseTimestamp(end); err != nil {
+		return Cue{}, err
+	}
+	return cue, nil
+}
+
+// Parses [hours:]minutes:seconds[.,]fraction as used by SRT, WebVTT, SubViewer and SSA/ASS
+func parseTimestamp(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	seconds, fraction, _ := strings.Cut(strings.Replace(parts[len(parts)-1], ",", ".", 1), ".")
+	// Fractions are tenths to milliseconds, ASS counts centiseconds
+	fraction = (fraction + "000")[:3]
+	var d time.Duration
+	for i, part := range append(parts[:len(parts)-1], seconds, fraction) {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		switch len(parts) + 2 - i {
+		case 5:
+			d += time.Duration(n) * time.Hour
+		case 4:
+			d += time.Duration(n) * time.Minute
+		case 3:
+			d += time.Duration(n) * time.Second
+		case 2:
+			d += time.Duration(n) * time.Millisecond
+		}
+	}
+	return d, nil
+}
+
+// Returns SSA/ASS dialogue text with italic, bold and underline overrides as tags and other overrides dropped
+func ssaText(text string) string {
+	text = overrideRe.ReplaceAllStringFunc(text, func(block string) string {
+		var tags strings.Builder
+		for _, match := range assStyleRe.FindAllStringSubmatch(block, -1) {
+			if match[2] == "1" {
+				tags.WriteString("<" + match[1] + ">")
+			} else {
+				tags.WriteString("</" + match[1] + ">")
+			}
+		}
+		return tags.String()
+	})
+	text = strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(text)
+	return cleanText(text)
+}
+
+// Returns text with markup other than <i>, <b> and <u> dropped and blank lines removed
+func cleanText(text string) string {
+	text = overrideRe.ReplaceAllString(text, "")
+	text = tagRe.ReplaceAllStringFunc(text, func(tag string) string {
+		match := tagRe.FindStringSubmatch(tag)
+		switch name := strings.ToLower(match[2]); name {
+		case "i", "b", "u":
+			return "<" + match[1] + name + ">"
+		}
+		return ""
+	})
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeSRT(cues []Cue) []byte {
+	var b bytes.Buffer
+	n := 0
+	for _, cue := range cues {
+		if cue.Text == "" {
+			continue
+		}
+		n++
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", n, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","), cue.Text)
+	}
+	return b.Bytes()
+}
+
+func writeVTT(cues []Cue) []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		if cue.Text == "" {
+			continue
+		}
+		// Kept styling tags are the only markup, any other < or & is text
+		text := strings.ReplaceAll(cue.Text, "&", "&amp;")
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), text)
+	}
+	return b.Bytes()
+}
+
+func formatTimestamp(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
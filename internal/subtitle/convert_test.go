@@ -0,0 +1,120 @@
+package subtitle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		string
+		from		string
+		to			string
+		expected	string
+	}{
+		{
+			name:		"ass with styling overrides",
+			input:		"[Script Info]\r\nScriptType: v4.00+\r\n\r\n[V4+ Styles]\r\nFormat: Name, Fontname\r\nStyle: Default,Arial\r\n\r\n[Events]\r\n" +
+				"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\r\n" +
+				"Dialogue: 0,0:00:04.50,0:00:06.00,Default,,0,0,0,,Second, with comma\r\n" +
+				"Comment: 0,0:00:02.00,0:00:03.00,Default,,0,0,0,,Not shown\r\n" +
+				"Dialogue: 0,0:00:01.00,0:00:03.25,Default,,0,0,0,,{\\an8\\i1}Hello{\\i0}\\Nworld\r\n",
+			from:		"ASS",
+			to:			"SRT",
+			expected:	"1\n00:00:01,000 --> 00:00:03,250\n<i>Hello</i>\nworld\n\n2\n00:00:04,500 --> 00:00:06,000\nSecond, with comma\n\n",
+		},
+		{
+			name:		"vtt with settings and voice",
+			input:		"WEBVTT\n\nNOTE made by hand\n\nintro\n00:01.000 --> 00:02.500 align:start\n<v Bob>Hi &amp; bye</v>\n\n01:00:00.000 --> 01:00:01.000\n<c.yellow>Later</c>\n",
+			from:		"VTT",
+			to:			"SRT",
+			expected:	"1\n00:00:01,000 --> 00:00:02,500\nHi & bye\n\n2\n01:00:00,000 --> 01:00:01,000\nLater\n\n",
+		},
+		{
+			name:		"sbv",
+			input:		"0:00:01.000,0:00:02.000\nFirst\n\n0:00:03.000,0:00:04.000\nSecond\nline\n",
+			from:		"SBV",
+			to:			"SRT",
+			expected:	"1\n00:00:01,000 --> 00:00:02,000\nFirst\n\n2\n00:00:03,000 --> 00:00:04,000\nSecond\nline\n\n",
+		},
+		{
+			name:		"smi cleared by nbsp",
+			input:		"<SAMI>\n<BODY>\n<SYNC Start=1000><P Class=ENCC><font color=\"yellow\">Hello</font><br>there\n<SYNC Start=2500><P Class=ENCC>&nbsp;\n<SYNC Start=3000><P Class=ENCC><i>Last</i>\n</BODY>\n</SAMI>\n",
+			from:		"SMI",
+			to:			"SRT",
+			expected:	"1\n00:00:01,000 --> 00:00:02,500\nHello\nthere\n\n2\n00:00:03,000 --> 00:00:08,000\n<i>Last</i>\n\n",
+		},
+		{
+			name:		"ttml with clock, offset and tick times",
+			input:		"<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<tt xmlns=\"http://www.w3.org/ns/ttml\" xmlns:tts=\"http://www.w3.org/ns/ttml#styling\" xmlns:ttp=\"http://www.w3.org/ns/ttml#parameter\" ttp:tickRate=\"10000000\">\n<body><div>\n" +
+				"<p begin=\"00:00:01.000\" end=\"00:00:02.000\">Hello<br/>\n  <span tts:fontStyle=\"italic\">world</span></p>\n" +
+				"<p begin=\"3s\" dur=\"500ms\">Offset</p>\n" +
+				"<p begin=\"40000000t\" end=\"50000000t\">Ticks</p>\n" +
+				"</div></body>\n</tt>\n",
+			from:		"TTML",
+			to:			"SRT",
+			expected:	"1\n00:00:01,000 --> 00:00:02,000\nHello\n<i>world</i>\n\n2\n00:00:03,000 --> 00:00:03,500\nOffset\n\n3\n00:00:04,000 --> 00:00:05,000\nTicks\n\n",
+		},
+		{
+			name:		"srt to vtt",
+			input:		"1\r\n00:00:01,000 --> 00:00:02,000\r\n{\\an8}<b>Tom & Jerry</b>\r\n\r\n",
+			from:		"SRT",
+			to:			"VTT",
+			expected:	"WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<b>Tom &amp; Jerry</b>\n\n",
+		},
+		{
+			name:		"same format only re-encodes",
+			input:		"1\n00:00:01,000 --> 00:00:02,000\nCaf\xE9 cr\xE8me, tr\xE8s bien, tr\xE8s s\xE9rieux, d\xE9j\xE0 vu\n",
+			from:		"SRT",
+			to:			"SRT",
+			expected:	"1\n00:00:01,000 --> 00:00:02,000\nCafé crème, très bien, très sérieux, déjà vu\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := Convert([]byte(test.input), test.from, test.to)
+			if err != nil {
+				t.Fatalf("Convert returns error %v", err)
+			}
+			if string(output) != test.expected {
+				t.Errorf("Convert = %q, want %q", output, test.expected)
+			}
+		})
+	}
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	if _, err := Convert([]byte("{1}{25}Hello\n"), "SUB", "SRT"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Convert from SUB error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+	if _, err := Convert([]byte(cue+"Hello\n"), "SRT", "ASS"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Convert to ASS error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct{
+		input		string
+		expected	time.Duration
+		ok			bool
+	}{
+		{input: "01:02:03,456", expected: time.Hour + 2*time.Minute + 3*time.Second + 456*time.Millisecond, ok: true},
+		{input: "02:03.456", expected: 2*time.Minute + 3*time.Second + 456*time.Millisecond, ok: true},
+		{input: "0:00:01.25", expected: 1250 * time.Millisecond, ok: true},
+		{input: "0:00:01", expected: time.Second, ok: true},
+		{input: "1.5", ok: false},
+		{input: "00:aa:01,000", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			d, err := parseTimestamp(test.input)
+			if (err == nil) != test.ok || d != test.expected {
+				t.Errorf("parseTimestamp = %v, %v, want %v, ok %v", d, err, test.expected, test.ok)
+			}
+		})
+	}
+}
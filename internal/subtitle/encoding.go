@@ -0,0 +1,295 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type Encoding string
+
+// Encodings subtitles are detected in
+const (
+	UTF8		Encoding = "utf-8"
+	UTF16LE		Encoding = "utf-16le"
+	UTF16BE		Encoding = "utf-16be"
+	Windows1252	Encoding = "windows-1252"	// Western European, superset of printable ISO-8859-1
+	Windows1251	Encoding = "windows-1251"	// Cyrillic
+	ISO88592	Encoding = "iso-8859-2"		// Central European
+	ISO88595	Encoding = "iso-8859-5"		// Cyrillic
+	ISO88597	Encoding = "iso-8859-7"		// Greek
+	ISO88599	Encoding = "iso-8859-9"		// Turkish
+)
+
+var ErrUndetectable = errors.New("undetectable encoding")
+
+// Plain text subtitle formats, XML based formats declare their own encoding and VobSub is binary
+var textFormats = []string{"SRT", "ASS", "SSA", "VTT", "SBV", "SMI", "SUB"}
+
+// Returns true if subtitles with upper cased ext are plain text, .sub may still hold VobSub data
+func IsText(ext string) bool {
+	return slices.Contains(textFormats, ext)
+}
+
+// Single-byte code page mapping bytes 0x80-0xFF to runes, utf8.RuneError marks undefined bytes
+type codePage struct {
+	encoding	Encoding
+	runes		[128]rune
+	weight		func(r rune) int	// How typical rune is of text in the code page's languages, 0 if untypical
+	script		bool				// Letters of a non-Latin script, never mixed with ASCII letters in one word
+}
+
+// Weight of characters typical of Latin code pages, about the mean letter frequency in percent of other scripts
+const latinWeight = 4
+
+// Letter frequencies in percent, rounded up to 1
+var (
+	russianFrequencies = map[rune]int{
+		'о': 11, 'е': 8, 'а': 8, 'и': 7, 'н': 7, 'т': 6, 'с': 5, 'р': 5, 'в': 5, 'л': 4, 'к': 3,
+		'м': 3, 'д': 3, 'п': 3, 'у': 3, 'я': 2, 'ы': 2, 'ь': 2, 'г': 2, 'з': 2, 'б': 2, 'ч': 1,
+		'й': 1, 'х': 1, 'ж': 1, 'ш': 1, 'ю': 1, 'ц': 1, 'щ': 1, 'э': 1, 'ф': 1, 'ъ': 1, 'ё': 1,
+	}
+	greekFrequencies = map[rune]int{
+		'α': 12, 'ο': 10, 'ι': 9, 'ε': 8, 'τ': 8, 'ν': 7, 'σ': 5, 'η': 5, 'υ': 4, 'ρ': 4, 'π': 4,
+		'κ': 4, 'ς': 3, 'μ': 3, 'λ': 3, 'ω': 2, 'δ': 2, 'γ': 2, 'ά': 2, 'έ': 2, 'ή': 2, 'ί': 2,
+		'ό': 2, 'χ': 1, 'θ': 1, 'φ': 1, 'β': 1, 'ξ': 1, 'ζ': 1, 'ψ': 1, 'ύ': 1, 'ώ': 1,
+	}
+)
+
+var codePages = []codePage{
+	newCodePage(Windows1252, latin1, map[byte]rune{
+		0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ',
+		0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“',
+		0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›',
+		0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+	}, oneOf("àáâãäåæçèéêëìíîïñòóôõöøùúûüÿœß‘’‚“”„–—…«»¡¿°€"), false),
+	newCodePage(ISO88592, latin1, map[byte]rune{
+		0xA1: 'Ą', 0xA2: '˘', 0xA3: 'Ł', 0xA5: 'Ľ', 0xA6: 'Ś', 0xA9: 'Š', 0xAA: 'Ş', 0xAB: 'Ť',
+		0xAC: 'Ź', 0xAE: 'Ž', 0xAF: 'Ż', 0xB1: 'ą', 0xB2: '˛', 0xB3: 'ł', 0xB5: 'ľ', 0xB6: 'ś',
+		0xB7: 'ˇ', 0xB9: 'š', 0xBA: 'ş', 0xBB: 'ť', 0xBC: 'ź', 0xBD: '˝', 0xBE: 'ž', 0xBF: 'ż',
+		0xC0: 'Ŕ', 0xC3: 'Ă', 0xC5: 'Ĺ', 0xC6: 'Ć', 0xC8: 'Č', 0xCA: 'Ę', 0xCC: 'Ě', 0xCF: 'Ď',
+		0xD0: 'Đ', 0xD1: 'Ń', 0xD2: 'Ň', 0xD5: 'Ő', 0xD8: 'Ř', 0xD9: 'Ů', 0xDB: 'Ű', 0xDE: 'Ţ',
+		0xE0: 'ŕ', 0xE3: 'ă', 0xE5: 'ĺ', 0xE6: 'ć', 0xE8: 'č', 0xEA: 'ę', 0xEC: 'ě', 0xEF: 'ď',
+		0xF0: 'đ', 0xF1: 'ń', 0xF2: 'ň', 0xF5: 'ő', 0xF8: 'ř', 0xF9: 'ů', 0xFB: 'ű', 0xFE: 'ţ',
+		0xFF: '˙',
+	}, oneOf("ąćčďęěĺľłńňőŕřśšťůűźżžáâäçéëíîóôöúüý"), false),
+	newCodePage(ISO88599, latin1, map[byte]rune{
+		0xD0: 'Ğ', 0xDD: 'İ', 0xDE: 'Ş', 0xF0: 'ğ', 0xFD: 'ı', 0xFE: 'ş',
+	}, oneOf("çğıöşüâîû"), false),
+	newCodePage(Windows1251, cyrillic(0xC0), map[byte]rune{
+		0x80: 'Ђ', 0x81: 'Ѓ', 0x82: '‚', 0x83: 'ѓ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+		0x88: '€', 0x89: '‰', 0x8A: 'Љ', 0x8B: '‹', 0x8C: 'Њ', 0x8D: 'Ќ', 0x8E: 'Ћ', 0x8F: 'Џ',
+		0x90: 'ђ', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+		0x99: '™', 0x9A: 'љ', 0x9B: '›', 0x9C: 'њ', 0x9D: 'ќ', 0x9E: 'ћ', 0x9F: 'џ',
+		0xA0: '\u00A0', 0xA1: 'Ў', 0xA2: 'ў', 0xA3: 'Ј', 0xA4: '¤', 0xA5: 'Ґ', 0xA6: '¦', 0xA7: '§',
+		0xA8: 'Ё', 0xA9: '©', 0xAA: 'Є', 0xAB: '«', 0xAC: '¬', 0xAD: '\u00AD', 0xAE: '®', 0xAF: 'Ї',
+		0xB0: '°', 0xB1: '±', 0xB2: 'І', 0xB3: 'і', 0xB4: 'ґ', 0xB5: 'µ', 0xB6: '¶', 0xB7: '·',
+		0xB8: 'ё', 0xB9: '№', 0xBA: 'є', 0xBB: '»', 0xBC: 'ј', 0xBD: 'Ѕ', 0xBE: 'ѕ', 0xBF: 'ї',
+	}, frequencies(russianFrequencies), true),
+	newCodePage(ISO88595, cyrillic(0xB0), map[byte]rune{
+		0xA0: '\u00A0', 0xAD: '\u00AD', 0xF0: '№', 0xFD: '§',
+	}, frequencies(russianFrequencies), true),
+	newCodePage(ISO88597, greek, map[byte]rune{
+		0xA0: '\u00A0', 0xA1: '‘', 0xA2: '’', 0xA3: '£', 0xA4: '€', 0xA5: '₯', 0xA6: '¦', 0xA7: '§',
+		0xA8: '¨', 0xA9: '©', 0xAA: 'ͺ', 0xAB: '«', 0xAC: '¬', 0xAD: '\u00AD', 0xAF: '―',
+		0xB0: '°', 0xB1: '±', 0xB2: '²', 0xB3: '³', 0xB4: '΄', 0xB5: '΅', 0xB6: 'Ά', 0xB7: '·',
+		0xB8: 'Έ', 0xB9: 'Ή', 0xBA: 'Ί', 0xBB: '»', 0xBC: 'Ό', 0xBD: '½', 0xBE: 'Ύ', 0xBF: 'Ώ',
+	}, frequencies(greekFrequencies), true),
+}
+
+// Returns encoding of subtitle data, UTF-8 for plain ASCII
+// Single-byte code pages are told apart by how typical their decoded bytes are of their languages
+func DetectEncoding(data []byte) (Encoding, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return UTF16LE, nil
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return UTF16BE, nil
+	}
+	// NUL bytes of UTF-16 are valid UTF-8, so UTF-16 without byte order mark is ruled out first
+	if encoding, ok := detectUTF16(data); ok {
+		return encoding, nil
+	}
+	if utf8.Valid(data) {
+		return UTF8, nil
+	}
+
+	high := 0
+	for _, b := range data {
+		if b >= 0x80 {
+			high++
+		}
+	}
+
+	// Ties go to the first code page, the most common one
+	best, bestScore := Encoding(""), 0
+	for _, page := range codePages {
+		score, ok := page.score(data)
+		if ok && score > bestScore {
+			best, bestScore = page.encoding, score
+		}
+	}
+	// Fewer than half of the non-ASCII bytes reading as typical Latin characters is a guess, not a detection
+	if best == "" || bestScore*2 < high*latinWeight {
+		return "", ErrUndetectable
+	}
+	return best, nil
+}
+
+// Returns data converted to UTF-8 without byte order mark together with the detected encoding
+func ToUTF8(data []byte) ([]byte, Encoding, error) {
+	encoding, err := DetectEncoding(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch encoding {
+	case UTF8:
+		return bytes.TrimPrefix(data, []byte("\uFEFF")), encoding, nil
+	case UTF16LE, UTF16BE:
+		return decodeUTF16(data, encoding == UTF16BE), encoding, nil
+	}
+	for _, page := range codePages {
+		if page.encoding == encoding {
+			return page.decode(data), encoding, nil
+		}
+	}
+	return nil, "", ErrUndetectable
+}
+
+// Detects UTF-16 without byte order mark from NUL bytes of ASCII characters in every other position
+func detectUTF16(data []byte) (Encoding, bool) {
+	if len(data) < 2 || len(data)%2 != 0 {
+		return "", false
+	}
+	var even, odd int
+	for i := 0; i < len(data); i += 2 {
+		if data[i] == 0 {
+			even++
+		}
+		if data[i+1] == 0 {
+			odd++
+		}
+	}
+	pairs := len(data) / 2
+	switch {
+	case odd*10 > pairs*4 && even*10 < pairs:
+		return UTF16LE, true
+	case even*10 > pairs*4 && odd*10 < pairs:
+		return UTF16BE, true
+	}
+	return "", false
+}
+
+func decodeUTF16(data []byte, bigEndian bool) []byte {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	text := string(utf16.Decode(units))
+	return []byte(strings.TrimPrefix(text, "\uFEFF"))
+}
+
+// Returns summed weight of runes decoded from non-ASCII bytes, false if data holds bytes undefined in page
+func (page codePage) score(data []byte) (int, bool) {
+	score := 0
+	for i, b := range data {
+		if b < 0x80 {
+			continue
+		}
+		r := page.runes[b-0x80]
+		if r == utf8.RuneError {
+			return 0, false
+		}
+		if page.script && ((i > 0 && isASCIILetter(data[i-1])) || (i+1 < len(data) && isASCIILetter(data[i+1]))) {
+			continue
+		}
+		score += page.weight(unicode.ToLower(r))
+	}
+	return score, true
+}
+
+func (page codePage) decode(data []byte) []byte {
+	var builder strings.Builder
+	builder.Grow(len(data))
+	for _, b := range data {
+		if b < 0x80 {
+			builder.WriteByte(b)
+			continue
+		}
+		builder.WriteRune(page.runes[b-0x80])
+	}
+	return []byte(builder.String())
+}
+
+// Builds code page from base mapping of bytes 0x80-0xFF with overrides
+func newCodePage(encoding Encoding, base func(b byte) rune, overrides map[byte]rune, weight func(r rune) int, script bool) codePage {
+	page := codePage{encoding: encoding, weight: weight, script: script}
+	for i := range page.runes {
+		b := byte(0x80 + i)
+		page.runes[i] = base(b)
+		if r, ok := overrides[b]; ok {
+			page.runes[i] = r
+		}
+	}
+	return page
+}
+
+// ISO-8859-1, C1 control bytes never occur in subtitle text and are left undefined
+func latin1(b byte) rune {
+	if b < 0xA0 {
+		return utf8.RuneError
+	}
+	return rune(b)
+}
+
+// Returns mapping of the 64 Cyrillic letters А-я starting at byte first, ISO-8859-5 adds Ё-Џ and ё-џ around them
+func cyrillic(first byte) func(b byte) rune {
+	return func(b byte) rune {
+		switch {
+		case b >= first && int(b) < int(first)+64:
+			return 'А' + rune(b-first)
+		// ISO-8859-5 places Ё-Ќ and ё-ќ before and after А-я, skipping soft hyphen and section sign
+		case first == 0xB0 && b >= 0xA1 && b <= 0xAF && b != 0xAD:
+			return 'Ѐ' + rune(b-0xA0)
+		case first == 0xB0 && b >= 0xF1 && b <= 0xFF && b != 0xFD:
+			return 'ѐ' + rune(b-0xF0)
+		}
+		return utf8.RuneError
+	}
+}
+
+// ISO-8859-7 letters, bytes 0xC0-0xFE follow Unicode's Greek block, 0xD2 and 0xFF are undefined
+func greek(b byte) rune {
+	if b >= 0xC0 && b <= 0xFE && b != 0xD2 {
+		return 'ΐ' + rune(b-0xC0)
+	}
+	return utf8.RuneError
+}
+
+func oneOf(runes string) func(r rune) int {
+	return func(r rune) int {
+		if strings.ContainsRune(runes, r) {
+			return latinWeight
+		}
+		return 0
+	}
+}
+
+func frequencies(weights map[rune]int) func(r rune) int {
+	return func(r rune) int {
+		return weights[r]
+	}
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
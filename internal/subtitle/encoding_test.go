@@ -0,0 +1,106 @@
+package subtitle
+
+import (
+	"errors"
+	"testing"
+)
+
+const cue = "1\n00:00:01,000 --> 00:00:02,000\n"
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		string
+		encoding	Encoding
+		expected	string
+	}{
+		{
+			name:		"ascii",
+			input:		cue + "Hello.\n",
+			encoding:	UTF8,
+			expected:	cue + "Hello.\n",
+		},
+		{
+			name:		"utf-8 with byte order mark",
+			input:		"\xEF\xBB\xBF" + cue + "Ça va.\n",
+			encoding:	UTF8,
+			expected:	cue + "Ça va.\n",
+		},
+		{
+			name:		"utf-16le with byte order mark",
+			input:		"\xFF\xFEH\x00i\x00\n\x00",
+			encoding:	UTF16LE,
+			expected:	"Hi\n",
+		},
+		{
+			name:		"utf-16be without byte order mark",
+			input:		"\x00H\x00i\x00!\x00\n",
+			encoding:	UTF16BE,
+			expected:	"Hi!\n",
+		},
+		{
+			name:		"windows-1252",
+			input:		cue + "C\x92est d\xE9j\xE0 l\x92\xE9t\xE9, gar\xE7on.\n",
+			encoding:	Windows1252,
+			expected:	cue + "C’est déjà l’été, garçon.\n",
+		},
+		{
+			name:		"windows-1252 italian",
+			input:		cue + "Perch\xE9 \xE8 gi\xE0 cos\xEC? Perch\xE9 s\xEC, pi\xF9 o meno.\n",
+			encoding:	Windows1252,
+			expected:	cue + "Perché è già così? Perché sì, più o meno.\n",
+		},
+		{
+			name:		"iso-8859-2",
+			input:		cue + "D\xECkuji, \xBEe jsi p\xF8i\xB9el. \xAElu\xBBou\xE8k\xFD k\xF9\xF2.\n",
+			encoding:	ISO88592,
+			expected:	cue + "Děkuji, že jsi přišel. Žluťoučký kůň.\n",
+		},
+		{
+			name:		"iso-8859-9",
+			input:		cue + "Te\xFEekk\xFCrler, a\xF0abey. I\xFE\xFDk nerede?\n",
+			encoding:	ISO88599,
+			expected:	cue + "Teşekkürler, ağabey. Işık nerede?\n",
+		},
+		{
+			name:		"windows-1251",
+			input:		cue + "\xCF\xF0\xE8\xE2\xE5\xF2, \xEA\xE0\xEA \xE4\xE5\xEB\xE0? \xC2\xF1\xB8 \xF5\xEE\xF0\xEE\xF8\xEE.\n",
+			encoding:	Windows1251,
+			expected:	cue + "Привет, как дела? Всё хорошо.\n",
+		},
+		{
+			name:		"iso-8859-5",
+			input:		cue + "\xBF\xE0\xD8\xD2\xD5\xE2, \xDA\xD0\xDA \xD4\xD5\xDB\xD0? \xB2\xE1\xF1 \xE5\xDE\xE0\xDE\xE8\xDE.\n",
+			encoding:	ISO88595,
+			expected:	cue + "Привет, как дела? Всё хорошо.\n",
+		},
+		{
+			name:		"iso-8859-7",
+			input:		cue + "\xCA\xE1\xEB\xE7\xEC\xDD\xF1\xE1, \xF4\xE9 \xEA\xDC\xED\xE5\xE9\xF2; \xBC\xEB\xE1 \xEA\xE1\xEB\xDC.\n",
+			encoding:	ISO88597,
+			expected:	cue + "Καλημέρα, τι κάνεις; Όλα καλά.\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, encoding, err := ToUTF8([]byte(test.input))
+			if err != nil {
+				t.Fatalf("ToUTF8 returns error %v", err)
+			}
+			if encoding != test.encoding {
+				t.Errorf("ToUTF8 encoding = %v, want %v", encoding, test.encoding)
+			}
+			if string(res) != test.expected {
+				t.Errorf("ToUTF8 = %q, want %q", res, test.expected)
+			}
+		})
+	}
+}
+
+func TestDetectEncodingUndetectable(t *testing.T) {
+	_, err := DetectEncoding([]byte(cue + "\x98\x98\x98 \x98\x98\n"))
+	if !errors.Is(err, ErrUndetectable) {
+		t.Errorf("DetectEncoding error = %v, want %v", err, ErrUndetectable)
+	}
+}
@@ -0,0 +1,203 @@
+package subtitle
+
+import (
+	"bufio"
+	"embed"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Ranked n-gram profiles of Latin script languages named by ISO 639 code, most frequent n-gram first
+//
+//go:embed profiles/*.txt
+var profileFiles embed.FS
+
+// Number of most frequent n-grams compared between text and profiles
+const profileSize = 300
+
+// Minimum letters of dialogue needed to guess its language
+const minLetters = 100
+
+// Detected languages below this confidence are guesses rather than detections
+const MinConfidence = 0.05
+
+// Non-Latin scripts used by a single language of patterns.LanguageCodes
+var scripts = []struct{
+	language	string
+	table		*unicode.RangeTable
+}{
+	{language: "ru", table: unicode.Cyrillic},
+	{language: "el", table: unicode.Greek},
+	{language: "ar", table: unicode.Arabic},
+	{language: "he", table: unicode.Hebrew},
+	{language: "th", table: unicode.Thai},
+	{language: "ko", table: unicode.Hangul},
+	{language: "ja", table: unicode.Hiragana},
+	{language: "ja", table: unicode.Katakana},
+	{language: "zh", table: unicode.Han},
+}
+
+var (
+	markupRe	= regexp.MustCompile(`<[^>]*>|\{[^}]*\}|&[a-zA-Z]+;|\\[Nnh]`)
+	dialogueRe	= regexp.MustCompile(`^Dialogue:\s*(?:[^,]*,){9}(.*)$`)
+)
+
+// Returns profiles by language, each mapping n-gram to its rank
+var getProfiles = sync.OnceValue(func() map[string]map[string]int {
+	profiles := make(map[string]map[string]int)
+	entries, _ := profileFiles.ReadDir("profiles")
+	for _, entry := range entries {
+		file, err := profileFiles.Open(path.Join("profiles", entry.Name()))
+		if err != nil {
+			continue
+		}
+		ranks := make(map[string]int)
+		scanner := bufio.NewScanner(file)
+		for rank := 0; scanner.Scan() && rank < profileSize; rank++ {
+			ranks[scanner.Text()] = rank
+		}
+		file.Close()
+		profiles[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = ranks
+	}
+	return profiles
+})
+
+// Returns ISO 639 code of the language subtitle data is written in and the confidence of the guess from 0 to 1
+// Returns "" and 0 if the encoding is undetectable or the dialogue too short to tell
+func DetectLanguage(data []byte) (string, float64) {
+	text, _, err := ToUTF8(data)
+	if err != nil {
+		return "", 0
+	}
+	dialogue := extractDialogue(string(text))
+
+	letters, counts := 0, make(map[string]int)
+	for _, r := range dialogue {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[script.language]++
+				break
+			}
+		}
+	}
+	if letters < minLetters {
+		return "", 0
+	}
+
+	// Japanese mixes kana with Han characters, Chinese never uses kana
+	if counts["ja"] > 0 && counts["ja"]*10 >= counts["ja"]+counts["zh"] {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+	script, scriptLetters := "", 0
+	for language, count := range counts {
+		if count > scriptLetters {
+			script, scriptLetters = language, count
+		}
+	}
+	if scriptLetters*2 > letters {
+		return script, float64(scriptLetters) / float64(letters)
+	}
+
+	return detectLatin(dialogue)
+}
+
+// Returns closest profile to n-grams of text and confidence from the gap between the two closest profiles
+func detectLatin(text string) (string, float64) {
+	grams := rankNgrams(text)
+	if len(grams) == 0 {
+		return "", 0
+	}
+
+	best, bestDist, secondDist := "", math.MaxInt, math.MaxInt
+	for language, ranks := range getProfiles() {
+		dist := 0
+		for rank, gram := range grams {
+			if profileRank, ok := ranks[gram]; ok {
+				dist += abs(rank - profileRank)
+			} else {
+				dist += profileSize
+			}
+		}
+		// Ties go to the alphabetically first language so detection is deterministic
+		if dist < bestDist || (dist == bestDist && language < best) {
+			best, bestDist, secondDist = language, dist, bestDist
+		} else if dist < secondDist {
+			secondDist = dist
+		}
+	}
+	if secondDist == 0 || secondDist == math.MaxInt {
+		return best, 0
+	}
+	return best, float64(secondDist-bestDist) / float64(secondDist)
+}
+
+// Returns 1 to 3 letter n-grams of lower cased words padded by "_", most frequent first
+func rankNgrams(text string) []string {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune("_" + word + "_")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				if gram := string(runes[i:i+n]); gram != "_" {
+					counts[gram]++
+				}
+			}
+		}
+	}
+
+	grams := make([]string, 0, len(counts))
+	for gram := range counts {
+		grams = append(grams, gram)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+	return grams
+}
+
+// Returns spoken text of subtitle, dropping markup and the style sections of SSA/ASS scripts
+// Timings and counters hold no letters so they are left in place
+func extractDialogue(text string) string {
+	script := strings.Contains(text, "[Events]")
+
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if script {
+			match := dialogueRe.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			line = match[1]
+		}
+		b.WriteString(markupRe.ReplaceAllString(line, " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
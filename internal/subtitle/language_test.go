@@ -0,0 +1,108 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct{
+		name		string
+		input		string
+		expected	string
+	}{
+		{
+			name:		"english srt with markup",
+			input:		cue + "<i>I have no idea where we are going tonight.</i>\n\n2\n00:00:04,000 --> 00:00:06,000\n" +
+				"Maybe we should call your mother and ask her about the road.\n{\\an8}She always knows the way home when we are lost.\n",
+			expected:	"en",
+		},
+		{
+			name:		"spanish",
+			input:		cue + "No tengo ni idea de adónde vamos esta noche. Quizás deberíamos llamar a tu madre y preguntarle por el camino. Ella siempre sabe volver a casa cuando estamos perdidos.\n",
+			expected:	"es",
+		},
+		{
+			name:		"french in windows-1252",
+			input:		cue + "Je n'ai aucune id\xE9e d'o\xF9 nous allons ce soir. On devrait peut-\xEAtre appeler ta m\xE8re et lui demander la route. Elle sait toujours rentrer quand nous sommes perdus.\n",
+			expected:	"fr",
+		},
+		{
+			name:		"german",
+			input:		cue + "Ich habe keine Ahnung, wohin wir heute Abend fahren. Vielleicht sollten wir deine Mutter anrufen und sie nach dem Weg fragen. Sie findet immer nach Hause.\n",
+			expected:	"de",
+		},
+		{
+			name:		"italian",
+			input:		cue + "Non ho idea di dove stiamo andando stasera. Forse dovremmo chiamare tua madre e chiederle la strada. Lei sa sempre come tornare a casa quando ci perdiamo.\n",
+			expected:	"it",
+		},
+		{
+			name:		"portuguese",
+			input:		cue + "Não faço ideia de para onde vamos hoje à noite. Talvez devêssemos ligar para a sua mãe e perguntar o caminho. Ela sempre sabe voltar para casa quando estamos perdidos.\n",
+			expected:	"pt",
+		},
+		{
+			name:		"turkish",
+			input:		cue + "Bu gece nereye gittiğimiz hakkında hiçbir fikrim yok. Belki annene telefon edip yolu sormalıyız. Kaybolduğumuzda eve dönmenin yolunu her zaman bilir.\n",
+			expected:	"tr",
+		},
+		{
+			name:		"polish",
+			input:		cue + "Nie mam pojęcia, dokąd dziś jedziemy. Może powinniśmy zadzwonić do twojej matki i zapytać o drogę. Ona zawsze wie, jak wrócić do domu, kiedy się zgubimy.\n",
+			expected:	"pl",
+		},
+		{
+			name:		"czech",
+			input:		cue + "Nemám tušení, kam dnes večer jedeme. Možná bychom měli zavolat tvojí mámě a zeptat se na cestu. Ona vždycky ví, jak se vrátit domů, když zabloudíme.\n",
+			expected:	"cs",
+		},
+		{
+			name:		"hungarian",
+			input:		cue + "Fogalmam sincs, hová megyünk ma este. Talán fel kellene hívnunk az anyádat, és megkérdezni az utat. Ő mindig tudja, hogyan jussunk haza, amikor eltévedünk.\n",
+			expected:	"hu",
+		},
+		{
+			name:		"russian",
+			input:		cue + "Я понятия не имею, куда мы едем сегодня вечером. Может, стоит позвонить твоей маме и спросить дорогу? Она всегда знает, как вернуться домой.\n",
+			expected:	"ru",
+		},
+		{
+			name:		"japanese",
+			input:		cue + strings.Repeat("今夜どこへ行くのか全然わからない。お母さんに電話して道を聞いたほうがいいかもしれない。\n", 3),
+			expected:	"ja",
+		},
+		{
+			name:		"chinese",
+			input:		cue + strings.Repeat("我完全不知道我们今晚要去哪里。也许我们应该给你妈妈打电话问路。\n", 4),
+			expected:	"zh",
+		},
+		{
+			name:		"ass script dialogue only",
+			input:		"[Script Info]\nTitle: Untitled\n\n[V4+ Styles]\nStyle: Default,Arial,20,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,2,2,2,10,10,10,1\n\n[Events]\n" +
+				"Dialogue: 0,0:00:01.00,0:00:03.00,Default,,0,0,0,,Ich habe keine Ahnung, wohin wir heute Abend fahren.\\NVielleicht sollten wir deine Mutter anrufen.\n" +
+				"Dialogue: 0,0:00:04.00,0:00:06.00,Default,,0,0,0,,{\\i1}Sie findet immer nach Hause, wenn wir uns verlaufen haben.{\\i0}\n",
+			expected:	"de",
+		},
+		{
+			name:		"too short",
+			input:		cue + "Hello there.\n",
+			expected:	"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			language, confidence := DetectLanguage([]byte(test.input))
+			if language != test.expected {
+				t.Errorf("DetectLanguage = %v, want %v", language, test.expected)
+			}
+			if test.expected != "" && confidence < MinConfidence {
+				t.Errorf("DetectLanguage confidence = %v, want at least %v", confidence, MinConfidence)
+			}
+			if test.expected == "" && confidence != 0 {
+				t.Errorf("DetectLanguage confidence = %v, want 0", confidence)
+			}
+		})
+	}
+}